@@ -0,0 +1,253 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package avi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/internal/fraction"
+	"github.com/Dadido3/mxv-demuxer/internal/waveformat"
+)
+
+type mainHeader struct {
+	MicroSecPerFrame    uint32
+	MaxBytesPerSec      uint32
+	PaddingGranularity  uint32
+	Flags               uint32
+	TotalFrames         uint32 // Number of video frames in the first RIFF segment.
+	InitialFrames       uint32
+	Streams             uint32
+	SuggestedBufferSize uint32
+	Width               uint32
+	Height              uint32
+	Reserved            [4]uint32
+}
+
+type streamHeader struct {
+	Type                [4]byte
+	Handler             [4]byte
+	Flags               uint32
+	Priority            uint16
+	Language            uint16
+	InitialFrames       uint32
+	Scale               uint32
+	Rate                uint32 // Rate / Scale is the number of time units per second.
+	Start               uint32
+	Length              uint32 // Length of the stream in time units.
+	SuggestedBufferSize uint32
+	Quality             uint32
+	SampleSize          uint32
+	Frame               [4]int16
+}
+
+type bitmapInfoHeader struct {
+	Size          uint32
+	Width         int32
+	Height        int32
+	Planes        uint16
+	BitCount      uint16
+	Compression   [4]byte
+	SizeImage     uint32
+	XPelsPerMeter int32
+	YPelsPerMeter int32
+	ClrUsed       uint32
+	ClrImportant  uint32
+}
+
+// Video properties header, as defined by OpenDML.
+type videoPropertiesHeader struct {
+	VideoFormatToken    uint32
+	VideoStandard       uint32
+	VerticalRefreshRate uint32
+	HTotalInT           uint32
+	VTotalInLines       uint32
+	FrameAspectRatio    uint32 // Display aspect ratio with the numerator in the upper and the denominator in the lower 16 bits.
+	FrameWidthInPixels  uint32
+	FrameHeightInLines  uint32
+	FieldPerFrame       uint32
+}
+
+// videoFieldDesc describes a single field of a frame. It follows the videoPropertiesHeader once for every field.
+type videoFieldDesc struct {
+	CompressedBMHeight   uint32
+	CompressedBMWidth    uint32
+	ValidBMHeight        uint32
+	ValidBMWidth         uint32
+	ValidBMXOffset       uint32
+	ValidBMYOffset       uint32
+	VideoXOffsetInT      uint32
+	VideoYValidStartLine uint32
+}
+
+type superIndexHeader struct {
+	LongsPerEntry uint16
+	IndexSubType  uint8
+	IndexType     uint8
+	EntriesInUse  uint32
+	ChunkID       [4]byte
+	Reserved      [3]uint32
+}
+
+// chunkBuffer is used to encode chunks of the header list in memory.
+type chunkBuffer struct {
+	bytes.Buffer
+}
+
+// writeChunk writes a chunk with the given identifier that contains the given data.
+func (b *chunkBuffer) writeChunk(id [4]byte, data ...any) {
+	var content chunkBuffer
+	for _, d := range data {
+		binary.Write(&content, binary.LittleEndian, d) // Writing into a bytes.Buffer never fails for fixed size data.
+	}
+
+	b.writeRaw(id, uint32(content.Len()), content.Bytes())
+	if content.Len()%2 != 0 {
+		b.WriteByte(0)
+	}
+}
+
+// writeList writes a LIST chunk of the given type that contains the given sub-chunks.
+func (b *chunkBuffer) writeList(listType [4]byte, content *chunkBuffer) {
+	b.writeRaw([4]byte{'L', 'I', 'S', 'T'}, uint32(4+content.Len()), listType, content.Bytes())
+}
+
+func (b *chunkBuffer) writeRaw(data ...any) {
+	for _, d := range data {
+		binary.Write(b, binary.LittleEndian, d)
+	}
+}
+
+// headerList encodes the hdrl LIST chunk with all AVI headers.
+// The size of the encoded headers is independent of the number of written frames.
+func (w *Writer) headerList() []byte {
+	info := w.info
+
+	frameRate, frameScale := info.FramerateFraction()
+
+	streams := uint32(1)
+	if info.HasAudio {
+		streams++
+	}
+
+	maxBytesPerSec := uint32(math.Min(float64(w.video.maxChunkSize)*info.Framerate+float64(info.AudioByteRate), math.MaxUint32))
+
+	var hdrl chunkBuffer
+	hdrl.writeChunk([4]byte{'a', 'v', 'i', 'h'}, mainHeader{
+		MicroSecPerFrame:    uint32(math.Round(1e6 * float64(frameScale) / float64(frameRate))),
+		MaxBytesPerSec:      maxBytesPerSec,
+		Flags:               avifHasIndex | avifIsInterleave,
+		TotalFrames:         w.firstVideoFrames,
+		Streams:             streams,
+		SuggestedBufferSize: max(w.video.maxChunkSize, w.audio.maxChunkSize),
+		Width:               info.FrameWidth,
+		Height:              info.FrameHeight,
+	})
+
+	// Video stream.
+	var strl chunkBuffer
+	strl.writeChunk([4]byte{'s', 't', 'r', 'h'}, streamHeader{
+		Type:                [4]byte{'v', 'i', 'd', 's'},
+		Handler:             [4]byte{'M', 'J', 'P', 'G'},
+		Scale:               uint32(frameScale),
+		Rate:                uint32(frameRate),
+		Length:              w.video.length,
+		SuggestedBufferSize: w.video.maxChunkSize,
+		Quality:             math.MaxUint32,
+		Frame:               [4]int16{0, 0, int16(info.FrameWidth), int16(info.FrameHeight)},
+	})
+	strl.writeChunk([4]byte{'s', 't', 'r', 'f'}, bitmapInfoHeader{
+		Size:        40,
+		Width:       int32(info.FrameWidth),
+		Height:      int32(info.FrameHeight),
+		Planes:      1,
+		BitCount:    24,
+		Compression: [4]byte{'M', 'J', 'P', 'G'},
+		SizeImage:   info.FrameWidth * info.FrameHeight * 3,
+	})
+	w.video.writeSuperIndex(&strl)
+
+	aspectNum, aspectDen := fraction.Approximate(info.AspectRatio, math.MaxUint16)
+	if aspectNum > math.MaxUint16 || aspectNum == 0 {
+		aspectNum, aspectDen = fraction.Approximate(float64(info.FrameWidth)/float64(info.FrameHeight), math.MaxUint16)
+	}
+	// Interlaced frames are described as two fields with half the height each.
+	// The field order can't be stored, only the line each field starts at.
+	fields := []videoFieldDesc{{
+		CompressedBMHeight: info.FrameHeight,
+		CompressedBMWidth:  info.FrameWidth,
+		ValidBMHeight:      info.FrameHeight,
+		ValidBMWidth:       info.FrameWidth,
+	}}
+	if info.Interlaced {
+		fields = nil
+		for line := range uint32(2) {
+			fields = append(fields, videoFieldDesc{
+				CompressedBMHeight:   info.FrameHeight / 2,
+				CompressedBMWidth:    info.FrameWidth,
+				ValidBMHeight:        info.FrameHeight / 2,
+				ValidBMWidth:         info.FrameWidth,
+				VideoYValidStartLine: line,
+			})
+		}
+	}
+	strl.writeChunk([4]byte{'v', 'p', 'r', 'p'}, videoPropertiesHeader{
+		VerticalRefreshRate: uint32(math.Round(info.Framerate)),
+		HTotalInT:           info.FrameWidth,
+		VTotalInLines:       info.FrameHeight,
+		FrameAspectRatio:    uint32(aspectNum)<<16 | uint32(aspectDen),
+		FrameWidthInPixels:  info.FrameWidth,
+		FrameHeightInLines:  info.FrameHeight,
+		FieldPerFrame:       uint32(len(fields)),
+	}, fields)
+	hdrl.writeList([4]byte{'s', 't', 'r', 'l'}, &strl)
+
+	// Audio stream.
+	if info.HasAudio {
+		var strl chunkBuffer
+		strl.writeChunk([4]byte{'s', 't', 'r', 'h'}, streamHeader{
+			Type:                [4]byte{'a', 'u', 'd', 's'},
+			Scale:               uint32(info.AudioBytesPerSample),
+			Rate:                uint32(info.AudioBytesPerSample) * info.AudioSampleRate,
+			Length:              w.audio.length,
+			SuggestedBufferSize: w.audio.maxChunkSize,
+			Quality:             math.MaxUint32,
+			SampleSize:          uint32(info.AudioBytesPerSample),
+		})
+		if format, extensible := waveformat.New(info); extensible != nil {
+			strl.writeChunk([4]byte{'s', 't', 'r', 'f'}, format, extensible)
+		} else {
+			strl.writeChunk([4]byte{'s', 't', 'r', 'f'}, format, uint16(0)) // WAVEFORMATEX without extra information.
+		}
+		w.audio.writeSuperIndex(&strl)
+		hdrl.writeList([4]byte{'s', 't', 'r', 'l'}, &strl)
+	}
+
+	// OpenDML extended header.
+	var odml chunkBuffer
+	odml.writeChunk([4]byte{'d', 'm', 'l', 'h'}, w.video.length, [61]uint32{})
+	hdrl.writeList([4]byte{'o', 'd', 'm', 'l'}, &odml)
+
+	var result chunkBuffer
+	result.writeList([4]byte{'h', 'd', 'r', 'l'}, &hdrl)
+
+	return result.Bytes()
+}
+
+// writeSuperIndex writes the indx chunk of the stream.
+// The chunk has always room for superIndexEntries entries.
+func (s *stream) writeSuperIndex(b *chunkBuffer) {
+	entries := make([]superIndexEntry, superIndexEntries)
+	copy(entries, s.superIndexEntries)
+
+	b.writeChunk([4]byte{'i', 'n', 'd', 'x'}, superIndexHeader{
+		LongsPerEntry: 4,
+		IndexType:     0x00, // AVI_INDEX_OF_INDEXES.
+		EntriesInUse:  uint32(len(s.superIndexEntries)),
+		ChunkID:       s.chunkID,
+	}, entries)
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package avi
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// Remux writes all video and audio frames of the given MXV reader as AVI file into ws.
+//
+// The JPEG and audio data is copied as is, there is no transcoding.
+// The audio is interleaved with the video frames it belongs to, and gaps between audio frames are filled with silence, see mxv.AudioStream.
+func Remux(ws io.WriteSeeker, r *mxv.Reader) error {
+	if err := r.PrepareLookupTable(); err != nil {
+		return fmt.Errorf("failed to prepare lookup table: %w", err)
+	}
+
+	w, err := NewWriter(ws, r.Info)
+	if err != nil {
+		return fmt.Errorf("failed to create AVI writer: %w", err)
+	}
+
+	var stream *mxv.AudioStream
+	if r.Info.HasAudio {
+		if stream, err = r.AudioStream(); err != nil {
+			return fmt.Errorf("failed to get audio stream: %w", err)
+		}
+	}
+
+	var audioSamples uint64 // The number of already written audio samples.
+	writeAudioUntil := func(sample uint64) error {
+		for end := min(sample, stream.Samples()); audioSamples < end; {
+			samples := uint32(min(end-audioSamples, math.MaxUint32))
+			if err := w.WriteAudioFrame(stream, samples); err != nil {
+				return fmt.Errorf("failed to write audio at sample %d: %w", audioSamples, err)
+			}
+			audioSamples += uint64(samples)
+		}
+		return nil
+	}
+
+	for frame := range r.VideoFrames() {
+		frameReader, err := r.VideoFrameData(frame)
+		if err != nil {
+			return fmt.Errorf("failed to get video data stream of frame %d: %w", frame, err)
+		}
+		if err := w.WriteVideoFrame(frameReader); err != nil {
+			return fmt.Errorf("failed to write video frame %d: %w", frame, err)
+		}
+
+		// Write all audio samples until the end of this video frame.
+		if r.Info.HasAudio {
+			endSample, _ := r.Info.VideoFrameTimestamp(uint64(frame+1), uint64(r.Info.AudioSampleRate))
+			if err := writeAudioUntil(endSample); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Write any remaining audio samples.
+	if r.Info.HasAudio {
+		if err := writeAudioUntil(math.MaxUint64); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish AVI file: %w", err)
+	}
+
+	return nil
+}
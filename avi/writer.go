@@ -0,0 +1,397 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package avi implements an AVI muxer that writes MJPEG video and PCM audio from MXV files.
+//
+// The files are written with OpenDML (AVI 2.0) indices, so there is no file size limit.
+// A legacy idx1 index is written for the first RIFF segment, so that older software can read at least the first gigabyte.
+package avi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// The maximum size of a single RIFF segment.
+// The first segment should not exceed 1 GiB to stay compatible with software that doesn't support OpenDML.
+var maxSegmentSize int64 = 1 << 30
+
+// The maximum number of entries in the super indices.
+// As every RIFF segment needs one entry, this limits the file size to about 256 GiB.
+const superIndexEntries = 256
+
+// AVI header flags.
+const (
+	avifHasIndex     = 0x00000010
+	avifIsInterleave = 0x00000100
+	aviifKeyframe    = 0x00000010
+)
+
+// stream contains the state of a single stream.
+type stream struct {
+	chunkID [4]byte // The chunk identifier of the data chunks. Like "00dc".
+	indexID [4]byte // The chunk identifier of the standard index chunks. Like "ix00".
+
+	length            uint32 // Length of the stream in units of the stream's time scale.
+	maxChunkSize      uint32
+	segmentEntries    []indexEntry      // Standard index entries of the current RIFF segment.
+	superIndexEntries []superIndexEntry // One entry per written standard index chunk.
+}
+
+type indexEntry struct {
+	offset   int64 // File offset of the chunk data.
+	size     uint32
+	duration uint32 // Duration in units of the stream's time scale.
+}
+
+type superIndexEntry struct {
+	Offset   uint64 // File offset of the standard index chunk.
+	Size     uint32 // Size of the standard index chunk, including its header.
+	Duration uint32 // Duration of all chunks referenced by the standard index chunk.
+}
+
+type legacyIndexEntry struct {
+	ChunkID [4]byte
+	Flags   uint32
+	Offset  uint32 // Offset of the chunk relative to the "movi" identifier.
+	Size    uint32
+}
+
+// Writer creates AVI files with MJPEG video and uncompressed audio.
+//
+// If any method returns an error, the resulting file is most likely invalid.
+type Writer struct {
+	ws  io.WriteSeeker
+	pos int64 // Current file offset.
+
+	info mxv.Info
+
+	video stream
+	audio stream
+
+	segments         int   // Number of started RIFF segments.
+	segmentOffset    int64 // File offset of the current RIFF chunk.
+	moviOffset       int64 // File offset of the current movi LIST chunk.
+	legacyIndex      []legacyIndexEntry
+	firstVideoFrames uint32 // Number of video frames in the first RIFF segment.
+
+	closed bool
+}
+
+// NewWriter creates a new AVI writer that writes into the given io.WriteSeeker.
+//
+// The stream headers are filled from the given MXV info.
+// Close has to be called to finish the file.
+func NewWriter(ws io.WriteSeeker, info mxv.Info) (*Writer, error) {
+	if info.FrameWidth == 0 || info.FrameHeight == 0 {
+		return nil, fmt.Errorf("invalid frame size %dx%d", info.FrameWidth, info.FrameHeight)
+	}
+	if info.Framerate <= 0 {
+		return nil, fmt.Errorf("invalid framerate %v", info.Framerate)
+	}
+	if info.HasAudio && (info.AudioBytesPerSample == 0 || info.AudioSampleRate == 0) {
+		return nil, fmt.Errorf("invalid audio format: %d bytes per sample, %d samples/s", info.AudioBytesPerSample, info.AudioSampleRate)
+	}
+
+	w := &Writer{
+		ws:    ws,
+		info:  info,
+		video: stream{chunkID: [4]byte{'0', '0', 'd', 'c'}, indexID: [4]byte{'i', 'x', '0', '0'}},
+		audio: stream{chunkID: [4]byte{'0', '1', 'w', 'b'}, indexID: [4]byte{'i', 'x', '0', '1'}},
+	}
+
+	// Write the headers with preliminary values, they will be updated by Close.
+	if err := w.startSegment(); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// write writes the given data at the current file offset.
+func (w *Writer) write(data any) error {
+	if err := binary.Write(w.ws, binary.LittleEndian, data); err != nil {
+		return err
+	}
+	w.pos += int64(binary.Size(data))
+
+	return nil
+}
+
+// writeAt writes the given data at the given file offset, and restores the current file offset afterwards.
+func (w *Writer) writeAt(offset int64, data any) error {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+		return err
+	}
+
+	if wa, ok := w.ws.(io.WriterAt); ok {
+		_, err := wa.WriteAt(buf.Bytes(), offset)
+		return err
+	}
+
+	if _, err := w.ws.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := w.ws.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if _, err := w.ws.Seek(w.pos, io.SeekStart); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// startChunk writes a chunk header with the given identifier, and returns the file offset of the chunk.
+// The size field will be updated by endChunk.
+//
+// If listType is not nil, it is written as the first four bytes of the chunk data.
+func (w *Writer) startChunk(id [4]byte, listType *[4]byte) (int64, error) {
+	offset := w.pos
+
+	if err := w.write(id); err != nil {
+		return 0, err
+	}
+	if err := w.write(uint32(0)); err != nil {
+		return 0, err
+	}
+	if listType != nil {
+		if err := w.write(*listType); err != nil {
+			return 0, err
+		}
+	}
+
+	return offset, nil
+}
+
+// endChunk updates the size field of the chunk at the given offset, and pads the chunk to an even size.
+func (w *Writer) endChunk(offset int64) error {
+	size := w.pos - offset - 8
+	if size > math.MaxUint32 {
+		return fmt.Errorf("chunk at offset %d is too large: %d bytes", offset, size)
+	}
+	if err := w.writeAt(offset+4, uint32(size)); err != nil {
+		return fmt.Errorf("failed to update chunk size: %w", err)
+	}
+
+	if size%2 != 0 {
+		if err := w.write(uint8(0)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startSegment starts a new RIFF segment with its movi list.
+// The first segment also contains all headers.
+func (w *Writer) startSegment() error {
+	var err error
+
+	if w.segments == 0 {
+		if w.segmentOffset, err = w.startChunk([4]byte{'R', 'I', 'F', 'F'}, &[4]byte{'A', 'V', 'I', ' '}); err != nil {
+			return fmt.Errorf("failed to write RIFF chunk: %w", err)
+		}
+		if err := w.write(w.headerList()); err != nil {
+			return fmt.Errorf("failed to write headers: %w", err)
+		}
+	} else {
+		if w.segments >= superIndexEntries {
+			return fmt.Errorf("the maximum number of %d RIFF segments is reached", superIndexEntries)
+		}
+		if w.segmentOffset, err = w.startChunk([4]byte{'R', 'I', 'F', 'F'}, &[4]byte{'A', 'V', 'I', 'X'}); err != nil {
+			return fmt.Errorf("failed to write RIFF chunk: %w", err)
+		}
+	}
+
+	if w.moviOffset, err = w.startChunk([4]byte{'L', 'I', 'S', 'T'}, &[4]byte{'m', 'o', 'v', 'i'}); err != nil {
+		return fmt.Errorf("failed to write movi chunk: %w", err)
+	}
+
+	w.segments++
+
+	return nil
+}
+
+// endSegment writes the standard indices of the current segment and finishes its movi list and RIFF chunk.
+// The first segment also gets a legacy idx1 index.
+func (w *Writer) endSegment() error {
+	for _, s := range []*stream{&w.video, &w.audio} {
+		if err := w.writeStandardIndex(s); err != nil {
+			return fmt.Errorf("failed to write standard index: %w", err)
+		}
+	}
+
+	if err := w.endChunk(w.moviOffset); err != nil {
+		return fmt.Errorf("failed to finish movi chunk: %w", err)
+	}
+
+	if w.segments == 1 {
+		offset, err := w.startChunk([4]byte{'i', 'd', 'x', '1'}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to write idx1 chunk: %w", err)
+		}
+		if err := w.write(w.legacyIndex); err != nil {
+			return fmt.Errorf("failed to write idx1 entries: %w", err)
+		}
+		if err := w.endChunk(offset); err != nil {
+			return fmt.Errorf("failed to finish idx1 chunk: %w", err)
+		}
+		w.legacyIndex = nil
+	}
+
+	if err := w.endChunk(w.segmentOffset); err != nil {
+		return fmt.Errorf("failed to finish RIFF chunk: %w", err)
+	}
+
+	return nil
+}
+
+// writeStandardIndex writes an OpenDML standard index chunk for all chunks of the given stream in the current segment.
+func (w *Writer) writeStandardIndex(s *stream) error {
+	if len(s.segmentEntries) == 0 {
+		return nil
+	}
+
+	baseOffset := w.segmentOffset
+
+	offset, err := w.startChunk(s.indexID, nil)
+	if err != nil {
+		return err
+	}
+
+	header := struct {
+		LongsPerEntry uint16
+		IndexSubType  uint8
+		IndexType     uint8
+		EntriesInUse  uint32
+		ChunkID       [4]byte
+		BaseOffset    uint64
+		Reserved      uint32
+	}{
+		LongsPerEntry: 2,
+		IndexType:     0x01, // AVI_INDEX_OF_CHUNKS.
+		EntriesInUse:  uint32(len(s.segmentEntries)),
+		ChunkID:       s.chunkID,
+		BaseOffset:    uint64(baseOffset),
+	}
+	if err := w.write(header); err != nil {
+		return err
+	}
+
+	var duration uint32
+	entries := make([]uint32, 0, len(s.segmentEntries)*2)
+	for _, e := range s.segmentEntries {
+		entries = append(entries, uint32(e.offset-baseOffset), e.size) // All chunks are keyframes, therefore bit 31 is never set.
+		duration += e.duration
+	}
+	if err := w.write(entries); err != nil {
+		return err
+	}
+
+	if err := w.endChunk(offset); err != nil {
+		return err
+	}
+
+	s.superIndexEntries = append(s.superIndexEntries, superIndexEntry{Offset: uint64(offset), Size: uint32(w.pos - offset), Duration: duration})
+	s.segmentEntries = nil
+
+	return nil
+}
+
+// writeDataChunk writes a data chunk for the given stream.
+// The data is copied from r.
+func (w *Writer) writeDataChunk(s *stream, r io.Reader) error {
+	if w.closed {
+		return fmt.Errorf("the writer is already closed")
+	}
+
+	// Start a new segment if the current one is getting too large.
+	// As the size of the chunk is not known beforehand, a generous margin is used.
+	if w.pos-w.segmentOffset > maxSegmentSize-maxSegmentSize/64 {
+		if err := w.endSegment(); err != nil {
+			return err
+		}
+		if err := w.startSegment(); err != nil {
+			return err
+		}
+	}
+
+	offset, err := w.startChunk(s.chunkID, nil)
+	if err != nil {
+		return fmt.Errorf("failed to write chunk header: %w", err)
+	}
+	n, err := io.Copy(w.ws, r)
+	w.pos += n
+	if err != nil {
+		return fmt.Errorf("failed to copy chunk data: %w", err)
+	}
+	if err := w.endChunk(offset); err != nil {
+		return fmt.Errorf("failed to finish chunk: %w", err)
+	}
+
+	size := uint32(n)
+
+	// Video chunks always contain a single frame, audio chunks are measured in samples.
+	duration := uint32(1)
+	if s == &w.audio {
+		duration = size / uint32(w.info.AudioBytesPerSample)
+	}
+
+	s.segmentEntries = append(s.segmentEntries, indexEntry{offset: offset + 8, size: size, duration: duration})
+	s.length += duration
+	s.maxChunkSize = max(s.maxChunkSize, size)
+
+	if w.segments == 1 {
+		w.legacyIndex = append(w.legacyIndex, legacyIndexEntry{ChunkID: s.chunkID, Flags: aviifKeyframe, Offset: uint32(offset - (w.moviOffset + 8)), Size: size})
+		if s == &w.video {
+			w.firstVideoFrames++
+		}
+	}
+
+	return nil
+}
+
+// WriteVideoFrame adds a new video frame to the file.
+// The raw JPEG data is copied from the given io.Reader.
+func (w *Writer) WriteVideoFrame(r io.Reader) error {
+	return w.writeDataChunk(&w.video, r)
+}
+
+// WriteAudioFrame adds the given number of audio samples to the file.
+// The raw audio data is copied from the given io.Reader, its encoding has to match the audio format of the MXV info.
+func (w *Writer) WriteAudioFrame(r io.Reader, samples uint32) error {
+	if !w.info.HasAudio {
+		return fmt.Errorf("the file is written without audio")
+	}
+
+	return w.writeDataChunk(&w.audio, io.LimitReader(r, int64(samples)*int64(w.info.AudioBytesPerSample)))
+}
+
+// Close writes the remaining indices and updates the headers.
+// This doesn't close the underlying io.WriteSeeker.
+func (w *Writer) Close() error {
+	if w.closed {
+		return fmt.Errorf("the writer is already closed")
+	}
+	w.closed = true
+
+	if err := w.endSegment(); err != nil {
+		return err
+	}
+
+	// The header list directly follows the RIFF header at the start of the file.
+	if err := w.writeAt(12, w.headerList()); err != nil {
+		return fmt.Errorf("failed to update headers: %w", err)
+	}
+
+	return nil
+}
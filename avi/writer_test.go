@@ -0,0 +1,239 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package avi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// riffChunk is a parsed RIFF chunk of an AVI file.
+type riffChunk struct {
+	id       string
+	listType string // Only set for RIFF and LIST chunks.
+	offset   int64  // File offset of the chunk data.
+	data     []byte
+	children []riffChunk
+}
+
+// parseRIFFChunks parses all chunks in data, which starts at the given file offset.
+func parseRIFFChunks(t *testing.T, data []byte, offset int64) []riffChunk {
+	var chunks []riffChunk
+	for len(data) > 0 {
+		if len(data) < 8 {
+			t.Fatalf("Truncated chunk header at offset %d.", offset)
+		}
+		c := riffChunk{id: string(data[0:4])}
+		size := int64(binary.LittleEndian.Uint32(data[4:8]))
+		if int64(len(data)) < 8+size {
+			t.Fatalf("Chunk %q at offset %d goes beyond its parent.", c.id, offset)
+		}
+		c.offset, c.data = offset+8, data[8:8+size]
+		if c.id == "RIFF" || c.id == "LIST" {
+			c.listType = string(c.data[0:4])
+			c.children = parseRIFFChunks(t, c.data[4:], c.offset+4)
+		}
+		chunks = append(chunks, c)
+
+		size += size % 2
+		data, offset = data[8+size:], offset+8+size
+	}
+	return chunks
+}
+
+// find returns the first sub-chunk with the given identifier or list type.
+func (c riffChunk) find(t *testing.T, id string) riffChunk {
+	for _, child := range c.children {
+		if child.id == id || child.listType == id {
+			return child
+		}
+	}
+	t.Fatalf("Couldn't find %q in %q.", id, c.id)
+	return riffChunk{}
+}
+
+func TestRemux(t *testing.T) {
+	// Use small segments to test OpenDML indices.
+	defer func(size int64) { maxSegmentSize = size }(maxSegmentSize)
+	maxSegmentSize = 1 << 20
+
+	src, err := os.Open(filepath.Join("..", "example-files", "25i.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer src.Close()
+
+	mxvReader, err := mxv.NewReader(src)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	dstFilename := filepath.Join(t.TempDir(), "remuxed.avi")
+	dst, err := os.Create(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer dst.Close()
+
+	if err := Remux(dst, mxvReader); err != nil {
+		t.Fatalf("Failed to remux: %v.", err)
+	}
+
+	data, err := os.ReadFile(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+	segments := parseRIFFChunks(t, data, 0)
+	if len(segments) < 2 {
+		t.Fatalf("Expected several RIFF segments, got %d.", len(segments))
+	}
+	for i, segment := range segments {
+		wantType := "AVIX"
+		if i == 0 {
+			wantType = "AVI "
+		}
+		if segment.id != "RIFF" || segment.listType != wantType {
+			t.Errorf("Segment %d is of type %q %q, want %q %q.", i, segment.id, segment.listType, "RIFF", wantType)
+		}
+	}
+
+	hdrl := segments[0].find(t, "hdrl")
+	var avih mainHeader
+	binary.Read(bytes.NewReader(hdrl.find(t, "avih").data), binary.LittleEndian, &avih)
+	if avih.Width != 1440 || avih.Height != 1080 || avih.Streams != 2 {
+		t.Errorf("Unexpected main header %+v.", avih)
+	}
+	if got := binary.LittleEndian.Uint32(hdrl.find(t, "odml").find(t, "dmlh").data); got != 50 {
+		t.Errorf("Unexpected total number of frames. Got %d, want %d.", got, 50)
+	}
+
+	// Collect all chunks referenced by the OpenDML indices of the given stream.
+	readStream := func(strl riffChunk) [][]byte {
+		var result [][]byte
+
+		indx := strl.find(t, "indx").data
+		entries := binary.LittleEndian.Uint32(indx[4:8])
+		for i := range entries {
+			entry := indx[24+16*i:]
+			offset := int64(binary.LittleEndian.Uint64(entry[0:8]))
+
+			ix := parseRIFFChunks(t, data[offset:offset+8+int64(binary.LittleEndian.Uint32(data[offset+4:]))], offset)[0]
+			baseOffset := int64(binary.LittleEndian.Uint64(ix.data[12:20]))
+			for j := range binary.LittleEndian.Uint32(ix.data[4:8]) {
+				chunkOffset := baseOffset + int64(binary.LittleEndian.Uint32(ix.data[24+8*j:]))
+				chunkSize := int64(binary.LittleEndian.Uint32(ix.data[28+8*j:]))
+				if got, want := int64(binary.LittleEndian.Uint32(data[chunkOffset-4:])), chunkSize; got != want {
+					t.Errorf("Chunk size in index (%d) differs from chunk header (%d).", want, got)
+				}
+				result = append(result, data[chunkOffset:chunkOffset+chunkSize])
+			}
+		}
+
+		return result
+	}
+
+	var strls []riffChunk
+	for _, c := range hdrl.children {
+		if c.listType == "strl" {
+			strls = append(strls, c)
+		}
+	}
+	if len(strls) != 2 {
+		t.Fatalf("Unexpected number of streams. Got %d, want %d.", len(strls), 2)
+	}
+
+	videoChunks := readStream(strls[0])
+	if len(videoChunks) != int(mxvReader.Info.VideoFrames) {
+		t.Fatalf("Unexpected number of video chunks. Got %d, want %d.", len(videoChunks), mxvReader.Info.VideoFrames)
+	}
+	for frame := range mxvReader.VideoFrames() {
+		r, err := mxvReader.VideoFrameData(frame)
+		if err != nil {
+			t.Fatalf("Failed to get video data stream: %v.", err)
+		}
+		want, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read video data stream: %v.", err)
+		}
+		if !bytes.Equal(videoChunks[frame], want) {
+			t.Errorf("Video frame %d differs from source. Got %d bytes, want %d bytes.", frame, len(videoChunks[frame]), len(want))
+		}
+	}
+
+	var audioData []byte
+	for _, chunk := range readStream(strls[1]) {
+		audioData = append(audioData, chunk...)
+	}
+	if got, want := uint64(len(audioData)), mxvReader.Info.AudioSamples*uint64(mxvReader.Info.AudioBytesPerSample); got != want {
+		t.Errorf("Unexpected audio data length. Got %d bytes, want %d bytes.", got, want)
+	}
+
+	// The legacy index only covers the first segment.
+	idx1 := segments[0].find(t, "idx1")
+	movi := segments[0].find(t, "movi")
+	for i := 0; i < len(idx1.data); i += 16 {
+		offset := movi.offset + int64(binary.LittleEndian.Uint32(idx1.data[i+8:]))
+		if got, want := string(data[offset:offset+4]), string(idx1.data[i:i+4]); got != want {
+			t.Errorf("idx1 entry %d points to chunk %q, want %q.", i/16, got, want)
+		}
+	}
+}
+
+func TestRemuxInterlaced(t *testing.T) {
+	src, err := os.Open(filepath.Join("..", "example-files", "25i.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer src.Close()
+
+	mxvReader, err := mxv.NewReader(src)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	// The example file doesn't have the interlaced flag set.
+	mxvReader.Info.Interlaced = true
+
+	dstFilename := filepath.Join(t.TempDir(), "remuxed.avi")
+	dst, err := os.Create(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer dst.Close()
+
+	if err := Remux(dst, mxvReader); err != nil {
+		t.Fatalf("Failed to remux: %v.", err)
+	}
+
+	data, err := os.ReadFile(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+	segments := parseRIFFChunks(t, data, 0)
+
+	vprp := segments[0].find(t, "hdrl").find(t, "strl").find(t, "vprp").data
+	var header videoPropertiesHeader
+	r := bytes.NewReader(vprp)
+	binary.Read(r, binary.LittleEndian, &header)
+	if header.FieldPerFrame != 2 {
+		t.Fatalf("Unexpected number of fields per frame. Got %d, want %d.", header.FieldPerFrame, 2)
+	}
+	fields := make([]videoFieldDesc, header.FieldPerFrame)
+	if err := binary.Read(r, binary.LittleEndian, fields); err != nil {
+		t.Fatalf("Failed to read field descriptions: %v.", err)
+	}
+	for i, field := range fields {
+		want := videoFieldDesc{CompressedBMHeight: 540, CompressedBMWidth: 1440, ValidBMHeight: 540, ValidBMWidth: 1440, VideoYValidStartLine: uint32(i)}
+		if field != want {
+			t.Errorf("Unexpected description of field %d. Got %+v, want %+v.", i, field, want)
+		}
+	}
+}
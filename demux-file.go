@@ -6,25 +6,42 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/Dadido3/mxv-demuxer/mxv"
-	"github.com/moutend/go-wav"
+	"github.com/Dadido3/mxv-demuxer/wav"
 )
 
+// demuxOptions contains the settings for demuxing into a JPEG sequence and a WAV file.
+type demuxOptions struct {
+	jobs          int          // The number of video frames that are extracted concurrently.
+	timecodeStart mxv.Timecode // The timecode of the first video frame.
+	timecodeNames bool         // Name the video frames by their timecode instead of their frame number.
+	timecodeFile  bool         // Write a sidecar file with the timecode of every video frame.
+}
+
 // demuxFile will demux the given file and write the demuxed data streams into a subfolder with the name of the file.
-func demuxFile(filename string) error {
+func demuxFile(filename string, readerOptions mxv.ReaderOptions, options demuxOptions) error {
 
 	file, err := os.Open(filename)
 	if err != nil {
 		return fmt.Errorf("failed to open file: %w", err)
 	}
+	defer file.Close()
 
-	mxvReader, err := mxv.NewReader(file)
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to get file size: %w", err)
+	}
+
+	// Use positional reads, so that several frames can be read at once.
+	mxvReader, err := mxv.NewReaderAtWithOptions(file, fileInfo.Size(), readerOptions)
 	if err != nil {
 		return fmt.Errorf("failed to read MXV file: %w", err)
 	}
@@ -41,66 +58,223 @@ func demuxFile(filename string) error {
 	}
 
 	log.Printf("MXV info: %+v.", mxvReader.Info)
+	logFrameTableDisagreements(mxvReader)
 
-	// Video frames.
-	log.Printf("Extracting video frames.")
-	for frame := range mxvReader.VideoFrames() {
-		videoFilename := filepath.Join(outputPath, fmt.Sprintf("video-%06d.jpeg", frame))
-		file, err := os.Create(videoFilename)
-		if err != nil {
-			return fmt.Errorf("failed to create file: %w", err)
+	// Check the start timecode before any frame is written.
+	if options.timecodeNames || options.timecodeFile {
+		if _, err := mxvReader.Info.VideoFrameTimecode(0, options.timecodeStart); err != nil {
+			return fmt.Errorf("failed to determine timecode: %w", err)
 		}
-		frameReader, err := mxvReader.VideoFrameData(frame)
+	}
+	if options.timecodeNames {
+		wraps, err := timecodesWrap(mxvReader, options.timecodeStart)
 		if err != nil {
-			return fmt.Errorf("failed to get video data stream: %w", err)
+			return fmt.Errorf("failed to check timecodes: %w", err)
 		}
-		if _, err := io.Copy(file, frameReader); err != nil {
-			return fmt.Errorf("failed to copy video data stream: %w", err)
+		if wraps {
+			log.Printf("The timecodes wrap around after 24 hours, so several video frames would get the same name. The video frames are named by their frame number instead.")
+			options.timecodeNames = false
+		}
+	}
+
+	// Video frames.
+	log.Printf("Extracting video frames.")
+	if err := extractVideoFrames(mxvReader, outputPath, options); err != nil {
+		return err
+	}
+
+	if options.timecodeFile {
+		if err := writeTimecodeFile(mxvReader, filepath.Join(outputPath, "timecode.txt"), options); err != nil {
+			return fmt.Errorf("failed to write timecode file: %w", err)
 		}
 	}
 
 	log.Printf("Finished extracting video frames.")
 
 	if mxvReader.Info.HasAudio {
-		// TODO: go-wav doesn't support streaming, therefore replace it
+		audioFilename := filepath.Join(outputPath, "audio.wav")
+		log.Printf("Extracting audio samples into %q.", audioFilename)
 
-		// Set up empty wav object.
-		wavObject, err := wav.New(int(mxvReader.Info.AudioSampleRate), int(mxvReader.Info.AudioChannelBitDepth), int(mxvReader.Info.AudioChannels))
+		audioFile, err := os.Create(audioFilename)
 		if err != nil {
-			return fmt.Errorf("failed to create wav object: %w", err)
+			return fmt.Errorf("failed to create audio file: %w", err)
 		}
+		defer audioFile.Close()
 
-		// Append sample data.
-		log.Printf("Extracting audio samples.")
-		for frame := range mxvReader.AudioFrames() {
-			frameReader, _, _, err := mxvReader.AudioFrameData(frame)
-			if err != nil {
-				return fmt.Errorf("failed to get audio data stream: %w", err)
-			}
-			frameData, err := io.ReadAll(frameReader)
-			if err != nil {
-				return fmt.Errorf("failed to read audio data stream: %w", err)
-			}
-			if _, err := wavObject.Write(frameData); err != nil {
-				return fmt.Errorf("failed to append audio data to wave object: %w", err)
+		if err := wav.Remux(audioFile, mxvReader); err != nil {
+			return fmt.Errorf("failed to write audio file: %w", err)
+		}
+		if err := audioFile.Close(); err != nil {
+			return fmt.Errorf("failed to close audio file: %w", err)
+		}
+
+		log.Printf("Finished writing audio data.")
+	}
+
+	logReaderWarnings(mxvReader)
+	log.Printf("Completely demuxed %q.", filename)
+
+	return nil
+}
+
+// logFrameTableDisagreements compares the two video frame indices of the given reader, and logs any disagreements.
+func logFrameTableDisagreements(mxvReader *mxv.Reader) {
+	disagreements, err := mxvReader.CheckFrameTable()
+	if err != nil {
+		log.Printf("Failed to compare frame table with lookup list: %v.", err)
+		return
+	}
+	for _, d := range disagreements {
+		log.Printf("Frame table disagrees with lookup list: %v.", d)
+	}
+}
+
+// logReaderWarnings logs all problems that the reader ignored or repaired.
+func logReaderWarnings(mxvReader *mxv.Reader) {
+	for _, warning := range mxvReader.Warnings() {
+		log.Printf("%v.", warning)
+	}
+}
+
+// extractVideoFrames writes all video frames as JPEG files into outputPath, using the number of concurrent jobs in options.
+//
+// The name of every file only depends on its frame number, so the result is the same for any number of jobs.
+// Failed frames don't stop the extraction of other frames.
+// They are logged in frame order, and an error is returned if there is at least one failed frame.
+func extractVideoFrames(mxvReader *mxv.Reader, outputPath string, options demuxOptions) error {
+	var frames int
+	for range mxvReader.VideoFrames() {
+		frames++
+	}
+
+	errs := make([]error, frames)
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for range max(options.jobs, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for frame := range queue {
+				errs[frame] = extractVideoFrame(mxvReader, outputPath, frame, options)
 			}
+		}()
+	}
+
+	for frame := range frames {
+		queue <- frame
+	}
+	close(queue)
+	wg.Wait()
+
+	var failed int
+	for frame, err := range errs {
+		if err != nil {
+			log.Printf("Failed to extract video frame %d: %v.", frame, err)
+			failed++
 		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to extract %d of %d video frames", failed, frames)
+	}
 
-		// Write audio file to disk.
-		audioFilename := filepath.Join(outputPath, "audio.wav")
-		log.Printf("Writing extracted audio data to disk at %q.", audioFilename)
-		wavTemp, err := wav.Marshal(wavObject)
+	return nil
+}
+
+// extractVideoFrame writes the given video frame as JPEG file into outputPath.
+func extractVideoFrame(mxvReader *mxv.Reader, outputPath string, frame int, options demuxOptions) error {
+	frameReader, err := mxvReader.VideoFrameData(frame)
+	if err != nil {
+		return fmt.Errorf("failed to get video data stream: %w", err)
+	}
+
+	name, err := videoFrameName(mxvReader, frame, options)
+	if err != nil {
+		return err
+	}
+
+	videoFilename := filepath.Join(outputPath, name)
+	file, err := os.Create(videoFilename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, frameReader); err != nil {
+		return fmt.Errorf("failed to copy video data stream: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	return nil
+}
+
+// videoFrameName returns the file name of the given video frame.
+// Depending on the options, the name contains the frame number or the timecode of the frame.
+func videoFrameName(mxvReader *mxv.Reader, frame int, options demuxOptions) (string, error) {
+	if !options.timecodeNames {
+		return fmt.Sprintf("video-%06d.jpeg", frame), nil
+	}
+
+	tc, err := mxvReader.VideoFrameTimecode(frame, options.timecodeStart)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine timecode: %w", err)
+	}
+
+	return fmt.Sprintf("video-%02d_%02d_%02d_%02d.jpeg", tc.Hours, tc.Minutes, tc.Seconds, tc.Frames), nil
+}
+
+// timecodesWrap returns whether the timecodes of the video frames wrap around after 24 hours.
+// In that case, several video frames have the same timecode.
+func timecodesWrap(mxvReader *mxv.Reader, start mxv.Timecode) (bool, error) {
+	first, err := mxvReader.VideoFrameTimecode(0, start)
+	if err != nil {
+		return false, err
+	}
+
+	// The timecodes are consecutive, so the first repeated timecode is the one of the first frame.
+	for frame := 1; uint64(frame) < mxvReader.Info.VideoFrames; frame++ {
+		tc, err := mxvReader.VideoFrameTimecode(frame, start)
 		if err != nil {
-			return fmt.Errorf("failed to encode wave data: %w", err)
+			return false, err
 		}
-		if err := os.WriteFile(audioFilename, wavTemp, 0666); err != nil {
-			return fmt.Errorf("failed to write audio file: %w", err)
+		if tc == first {
+			return true, nil
 		}
+	}
 
-		log.Printf("Finished writing audio data.")
+	return false, nil
+}
+
+// writeTimecodeFile writes a text file that lists the frame number, timecode and file name of every video frame, separated by tabs.
+func writeTimecodeFile(mxvReader *mxv.Reader, filename string, options demuxOptions) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
 	}
+	defer file.Close()
 
-	log.Printf("Completely demuxed %q.", filename)
+	w := bufio.NewWriter(file)
+	for frame := range mxvReader.VideoFrames() {
+		tc, err := mxvReader.VideoFrameTimecode(frame, options.timecodeStart)
+		if err != nil {
+			return fmt.Errorf("failed to determine timecode of frame %d: %w", frame, err)
+		}
+		name, err := videoFrameName(mxvReader, frame, options)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%d\t%v\t%s\n", frame, tc, name)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
 
 	return nil
 }
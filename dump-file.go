@@ -0,0 +1,96 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// dumpedChunk contains the parsed fields of a chunk, and its raw data as hex string.
+type dumpedChunk struct {
+	Data any
+	Raw  string
+}
+
+// newDumpedChunk returns the dump of the given chunk data.
+// The data has to be a fixed size value, like the Data field of any chunk.
+func newDumpedChunk(data any) *dumpedChunk {
+	var raw bytes.Buffer
+	binary.Write(&raw, binary.LittleEndian, data) // Writing into a bytes.Buffer never fails for fixed size data.
+	return &dumpedChunk{Data: data, Raw: hex.EncodeToString(raw.Bytes())}
+}
+
+// dumpedChunkInfo describes the position of a top-level chunk.
+type dumpedChunkInfo struct {
+	Identifier string
+	Offset     int64
+	Length     int64
+}
+
+// dumpFile writes the video information, the list of all top-level chunks and the content of all header chunks as a single line of JSON to stdout.
+// The lines of several files can be compared to find out what the unknown values mean.
+func dumpFile(filename string, readerOptions mxv.ReaderOptions) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	mxvReader, err := mxv.NewReaderWithOptions(file, readerOptions)
+	if err != nil {
+		return fmt.Errorf("failed to read MXV file: %w", err)
+	}
+
+	dump := struct {
+		File     string
+		Info     mxv.Info
+		Chunks   []dumpedChunkInfo
+		MXJVH264 *dumpedChunk `json:",omitempty"`
+		MXJVHD64 *dumpedChunk `json:",omitempty"`
+		MXWFMT64 *dumpedChunk `json:",omitempty"`
+		MXJVCO64 *dumpedChunk `json:",omitempty"`
+		MXJVPD64 *dumpedChunk `json:",omitempty"`
+	}{
+		File: filename,
+		Info: mxvReader.Info,
+	}
+	for _, chunk := range mxvReader.Chunks() {
+		dump.Chunks = append(dump.Chunks, dumpedChunkInfo{Identifier: string(chunk.Identifier[:]), Offset: chunk.Offset, Length: chunk.Length})
+	}
+	headers := mxvReader.RawHeaders()
+	if headers.MXJVH264 != nil {
+		dump.MXJVH264 = newDumpedChunk(headers.MXJVH264)
+	}
+	if headers.MXJVHD64 != nil {
+		dump.MXJVHD64 = newDumpedChunk(headers.MXJVHD64)
+	}
+	if headers.MXWFMT64 != nil {
+		dump.MXWFMT64 = newDumpedChunk(headers.MXWFMT64)
+	}
+	if headers.MXJVCO64 != nil {
+		dump.MXJVCO64 = newDumpedChunk(headers.MXJVCO64)
+	}
+	if headers.MXJVPD64 != nil {
+		dump.MXJVPD64 = newDumpedChunk(headers.MXJVPD64)
+	}
+
+	line, err := json.Marshal(dump)
+	if err != nil {
+		return fmt.Errorf("failed to encode dump: %w", err)
+	}
+	if _, err := fmt.Fprintf(os.Stdout, "%s\n", line); err != nil {
+		return fmt.Errorf("failed to write dump: %w", err)
+	}
+
+	return nil
+}
@@ -5,5 +5,4 @@ go 1.24
 require (
 	github.com/earthboundkid/versioninfo/v2 v2.24.1
 	github.com/google/go-cmp v0.7.0
-	github.com/moutend/go-wav v0.0.0-20170820031854-56127fbbb7ba
 )
@@ -0,0 +1,56 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package fraction provides helpers to convert floating point values into fractions.
+package fraction
+
+import "math"
+
+// Approximate returns the fraction num/den that is closest to x, with den not exceeding maxDen.
+// The result is determined by continued fractions, and stops as soon as the fraction matches x within floating point precision.
+//
+// x has to be positive and finite, otherwise 0/1 is returned.
+func Approximate(x float64, maxDen uint64) (num, den uint64) {
+	if !(x > 0) || math.IsInf(x, 0) || maxDen == 0 {
+		return 0, 1
+	}
+
+	// Convergents h/k of the continued fraction of x.
+	var h0, h1, k0, k1 uint64 = 0, 1, 1, 0
+	rest := x
+	for {
+		a := math.Floor(rest)
+		if a > math.MaxUint32 {
+			break
+		}
+		ai := uint64(a)
+
+		h2, k2 := ai*h1+h0, ai*k1+k0
+		if k2 > maxDen {
+			// Check if a semi-convergent is closer than the last convergent.
+			if k1 > 0 {
+				n := (maxDen - k0) / k1
+				hs, ks := n*h1+h0, n*k1+k0
+				if ks > 0 && math.Abs(float64(hs)/float64(ks)-x) < math.Abs(float64(h1)/float64(k1)-x) {
+					return hs, ks
+				}
+			}
+			break
+		}
+		h0, h1, k0, k1 = h1, h2, k1, k2
+
+		frac := rest - a
+		if frac < 1e-9 || math.Abs(float64(h1)/float64(k1)-x) <= x*1e-12 {
+			break
+		}
+		rest = 1 / frac
+	}
+
+	if k1 == 0 {
+		return uint64(math.Round(x)), 1
+	}
+
+	return h1, k1
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package fraction_test
+
+import (
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/internal/fraction"
+)
+
+func TestApproximate(t *testing.T) {
+	tests := []struct {
+		x                float64
+		maxDen           uint64
+		wantNum, wantDen uint64
+	}{
+		{1.7777777777777777, 65535, 16, 9},
+		{1.3333332999999998, 100, 4, 3},
+		{1.7777777777777777 * 1080 / 1440, 65535, 4, 3},
+		{25, 65535, 25, 1},
+		{29.97, 65535, 2997, 100},
+		{0.5, 65535, 1, 2},
+		{3.141592653589793, 1000, 355, 113},
+		{0, 65535, 0, 1},
+		{-1, 65535, 0, 1},
+	}
+
+	for _, tt := range tests {
+		num, den := fraction.Approximate(tt.x, tt.maxDen)
+		if num != tt.wantNum || den != tt.wantDen {
+			t.Errorf("Approximate(%v, %d) = %d/%d, want %d/%d.", tt.x, tt.maxDen, num, den, tt.wantNum, tt.wantDen)
+		}
+	}
+}
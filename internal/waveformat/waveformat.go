@@ -0,0 +1,84 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package waveformat builds the wave format structures that describe the audio in WAV and AVI files.
+package waveformat
+
+import (
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// FormatTagExtensible is the format tag of WAVEFORMATEXTENSIBLE.
+// The actual format is stored in Extensible.SubFormat.
+const FormatTagExtensible = 0xFFFE
+
+// WaveFormat contains the fields of PCMWAVEFORMAT, which all other wave format structures start with.
+type WaveFormat struct {
+	FormatTag      uint16
+	Channels       uint16
+	SamplesPerSec  uint32
+	AvgBytesPerSec uint32
+	BlockAlign     uint16
+	BitsPerSample  uint16
+}
+
+// Extensible contains the fields that follow WaveFormat in WAVEFORMATEXTENSIBLE, starting with the cbSize field of WAVEFORMATEX.
+type Extensible struct {
+	Size               uint16 // The size of the remaining fields, which is always 22.
+	ValidBitsPerSample uint16
+	ChannelMask        uint32   // Assigns the channels to speaker positions, or 0 if they are not assigned.
+	SubFormat          [16]byte // The GUID of the format, which contains the actual format tag.
+}
+
+// Default speaker assignments by number of channels, as used by Windows.
+var channelMasks = []uint32{
+	1: 0x4,   // Front center.
+	2: 0x3,   // Front left, front right.
+	3: 0x7,   // Front left, front right, front center.
+	4: 0x33,  // Front left, front right, back left, back right.
+	5: 0x37,  // Front left, front right, front center, back left, back right.
+	6: 0x3F,  // 5.1: Front left, front right, front center, LFE, back left, back right.
+	7: 0x13F, // 6.1: 5.1 and back center.
+	8: 0x63F, // 7.1: 5.1 and side left, side right.
+}
+
+// New returns the wave format for the audio of the given MXV info.
+//
+// PCM with more than 2 channels or more than 16 bits per sample, and any format with more than 2 channels, can't be described unambiguously by WAVEFORMATEX.
+// In that case FormatTag is set to FormatTagExtensible, and the WAVEFORMATEXTENSIBLE fields are returned as well.
+// Otherwise the returned Extensible is nil.
+func New(info mxv.Info) (WaveFormat, *Extensible) {
+	format := WaveFormat{
+		FormatTag:      uint16(info.AudioFormat),
+		Channels:       info.AudioChannels,
+		SamplesPerSec:  info.AudioSampleRate,
+		AvgBytesPerSec: info.AudioSampleRate * uint32(info.AudioBytesPerSample),
+		BlockAlign:     info.AudioBytesPerSample,
+		BitsPerSample:  uint16(info.AudioChannelBitDepth),
+	}
+
+	if info.AudioChannels <= 2 && (info.AudioFormat != mxriff64.AudioFormatPCM || info.AudioChannelBitDepth <= 16) {
+		return format, nil
+	}
+
+	extensible := &Extensible{
+		Size:               22,
+		ValidBitsPerSample: uint16(info.AudioChannelBitDepth),
+		// The GUID is the format tag followed by the KSDATAFORMAT_SUBTYPE base GUID 0000xxxx-0000-0010-8000-00aa00389b71.
+		SubFormat: [16]byte{byte(info.AudioFormat), byte(info.AudioFormat >> 8), 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71},
+	}
+	if int(info.AudioChannels) < len(channelMasks) {
+		extensible.ChannelMask = channelMasks[info.AudioChannels]
+	}
+
+	// With WAVEFORMATEXTENSIBLE, BitsPerSample is the container size, which has to be a multiple of 8.
+	format.FormatTag = FormatTagExtensible
+	if info.AudioChannels > 0 {
+		format.BitsPerSample = info.AudioBytesPerSample / info.AudioChannels * 8
+	}
+
+	return format, extensible
+}
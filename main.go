@@ -10,11 +10,24 @@ import (
 	"io/ioutil"
 	"log"
 	"path/filepath"
+	"runtime"
 	"strings"
 
+	"github.com/Dadido3/mxv-demuxer/mxv"
 	"github.com/earthboundkid/versioninfo/v2"
 )
 
+var flagRecover = flag.Bool("recover", false, "Rebuild the frame index by scanning all frames in the file, instead of using the lookup tables. Use this for files of crashed captures.")
+var flagRepair = flag.Bool("repair", false, "Try to repair problems like contradicting headers, wrong frame counts and gaps or overlaps in the audio data, instead of failing.")
+var flagValidate = flag.Bool("validate", false, "Only check the files for problems and list them, without writing any output.")
+var flagDump = flag.Bool("dump", false, "Only write the video information, a list of all top-level chunks and the raw content of all header chunks as one line of JSON per file to stdout, without writing any output files.")
+var flagJobs = flag.Int("jobs", runtime.NumCPU(), "The number of video frames that are extracted concurrently. Only used for the \"jpeg\" output format.")
+var flagTimecodeStart = flag.String("timecode-start", "00:00:00:00", "The SMPTE timecode of the first video frame. Use a semicolon before the frames for drop-frame timecode, e.g. \"01:00:00;00\". Only used for the \"jpeg\" output format.")
+var flagTimecodeNames = flag.Bool("timecode-names", false, "Name the extracted video frames by their timecode, e.g. \"video-01_00_00_00.jpeg\", instead of their frame number. Only used for the \"jpeg\" output format.")
+var flagTimecodeFile = flag.Bool("timecode-file", false, "Write a sidecar file \"timecode.txt\" with the timecode of every video frame. Only used for the \"jpeg\" output format.")
+var flagFieldOrder = flag.String("field-order", "auto", "Override the interlacing of the video, as it can't be detected reliably. \"auto\" uses the video header, \"progressive\", \"top-first\" and \"bottom-first\" override it. Only used for the \"avi\", \"mkv\" and \"mov\" output formats.")
+var flagFormat = flag.String("format", "jpeg", "The output format. \"jpeg\" writes a JPEG sequence and a WAV file into a directory, \"avi\", \"mkv\" and \"mov\" write an AVI, Matroska or QuickTime file with MJPEG video and PCM audio.")
+
 func main() {
 	flag.Parse()
 
@@ -29,10 +42,67 @@ func main() {
 		}
 	}
 
+	switch *flagFormat {
+	case "jpeg", "avi", "mkv", "mov":
+	default:
+		log.Panicf("Unsupported output format %q.", *flagFormat)
+	}
+
+	switch *flagFieldOrder {
+	case "auto", "progressive", "top-first", "bottom-first":
+	default:
+		log.Panicf("Unsupported field order %q.", *flagFieldOrder)
+	}
+
+	readerOptions := mxv.ReaderOptions{Recover: *flagRecover}
+	if *flagRepair {
+		readerOptions.Policies = map[mxv.Check]mxv.Policy{}
+		for _, check := range []mxv.Check{mxv.CheckHeaderMismatch, mxv.CheckVideoFrameCount, mxv.CheckAudioFrameCount, mxv.CheckAudioSampleCount, mxv.CheckAudioContinuity, mxv.CheckChunkSize} {
+			readerOptions.Policies[check] = mxv.PolicyRepair
+		}
+	}
+
+	timecodeStart, err := mxv.ParseTimecode(*flagTimecodeStart)
+	if err != nil {
+		log.Panicf("Invalid start timecode: %v.", err)
+	}
+	demuxOptions := demuxOptions{
+		jobs:          *flagJobs,
+		timecodeStart: timecodeStart,
+		timecodeNames: *flagTimecodeNames,
+		timecodeFile:  *flagTimecodeFile,
+	}
+	remuxOptions := remuxOptions{
+		fieldOrder: *flagFieldOrder,
+	}
+
 	for _, filename := range filenames {
-		log.Printf("Starting to demux %q...", filename)
-		if err := demuxFile(filename); err != nil {
-			log.Printf("Failed to demux %q: %v", filename, err)
+		if *flagValidate {
+			log.Printf("Starting to validate %q...", filename)
+			if err := validateFile(filename); err != nil {
+				log.Printf("Failed to validate %q: %v", filename, err)
+			}
+			continue
+		}
+
+		if *flagDump {
+			if err := dumpFile(filename, readerOptions); err != nil {
+				log.Printf("Failed to dump %q: %v", filename, err)
+			}
+			continue
+		}
+
+		switch *flagFormat {
+		case "jpeg":
+			log.Printf("Starting to demux %q...", filename)
+			if err := demuxFile(filename, readerOptions, demuxOptions); err != nil {
+				log.Printf("Failed to demux %q: %v", filename, err)
+			}
+		default:
+			log.Printf("Starting to remux %q...", filename)
+			if err := remuxFile(filename, *flagFormat, readerOptions, remuxOptions); err != nil {
+				log.Printf("Failed to remux %q: %v", filename, err)
+			}
 		}
 	}
 }
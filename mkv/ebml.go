@@ -0,0 +1,140 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mkv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// EBML and Matroska element IDs, including their length markers.
+const (
+	idEBML               = 0x1A45DFA3
+	idEBMLVersion        = 0x4286
+	idEBMLReadVersion    = 0x42F7
+	idEBMLMaxIDLength    = 0x42F2
+	idEBMLMaxSizeLength  = 0x42F3
+	idDocType            = 0x4282
+	idDocTypeVersion     = 0x4287
+	idDocTypeReadVersion = 0x4285
+
+	idSegment      = 0x18538067
+	idSeekHead     = 0x114D9B74
+	idSeek         = 0x4DBB
+	idSeekID       = 0x53AB
+	idSeekPosition = 0x53AC
+
+	idInfo           = 0x1549A966
+	idTimestampScale = 0x2AD7B1
+	idDuration       = 0x4489
+	idMuxingApp      = 0x4D80
+	idWritingApp     = 0x5741
+
+	idTracks            = 0x1654AE6B
+	idTrackEntry        = 0xAE
+	idTrackNumber       = 0xD7
+	idTrackUID          = 0x73C5
+	idTrackType         = 0x83
+	idFlagLacing        = 0x9C
+	idDefaultDuration   = 0x23E383
+	idCodecID           = 0x86
+	idVideo             = 0xE0
+	idPixelWidth        = 0xB0
+	idPixelHeight       = 0xBA
+	idDisplayWidth      = 0x54B0
+	idDisplayHeight     = 0x54BA
+	idFlagInterlaced    = 0x9A
+	idFieldOrder        = 0x9D
+	idAudio             = 0xE1
+	idSamplingFrequency = 0xB5
+	idChannels          = 0x9F
+	idBitDepth          = 0x6264
+
+	idCluster       = 0x1F43B675
+	idTimestamp     = 0xE7
+	idSimpleBlock   = 0xA3
+	idBlockGroup    = 0xA0
+	idBlock         = 0xA1
+	idBlockDuration = 0x9B
+
+	idCues               = 0x1C53BB6B
+	idCuePoint           = 0xBB
+	idCueTime            = 0xB3
+	idCueTrackPositions  = 0xB7
+	idCueTrack           = 0xF7
+	idCueClusterPosition = 0xF1
+)
+
+// unknownSize is the size field of an element whose size is determined later.
+// It is encoded with the maximum length of 8 bytes, so that it can be replaced by the real size.
+var unknownSize = [8]byte{0x01, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+
+// encodeID returns the encoded form of the given element ID.
+func encodeID(id uint32) []byte {
+	b := binary.BigEndian.AppendUint32(nil, id)
+	for len(b) > 1 && b[0] == 0 {
+		b = b[1:]
+	}
+	return b
+}
+
+// encodeSize returns the shortest encoding of the given element data size.
+func encodeSize(size uint64) []byte {
+	length := 1
+	for length < 8 && size >= 1<<(7*length)-1 { // All ones are reserved for unknown sizes.
+		length++
+	}
+	return encodeSizeWidth(size, length)
+}
+
+// encodeSizeWidth encodes the given element data size with the given length in bytes.
+func encodeSizeWidth(size uint64, length int) []byte {
+	b := binary.BigEndian.AppendUint64(nil, size|1<<(7*length))
+	return b[8-length:]
+}
+
+// ebmlBuffer is used to encode EBML elements in memory.
+type ebmlBuffer struct {
+	bytes.Buffer
+}
+
+// writeElement writes an element with the given ID and raw data.
+func (b *ebmlBuffer) writeElement(id uint32, data []byte) {
+	b.Write(encodeID(id))
+	b.Write(encodeSize(uint64(len(data))))
+	b.Write(data)
+}
+
+// writeMaster writes a master element with the given ID that contains the given child elements.
+func (b *ebmlBuffer) writeMaster(id uint32, children *ebmlBuffer) {
+	b.writeElement(id, children.Bytes())
+}
+
+// writeUint writes an unsigned integer element with the shortest possible encoding.
+func (b *ebmlBuffer) writeUint(id uint32, value uint64) {
+	data := binary.BigEndian.AppendUint64(nil, value)
+	for len(data) > 1 && data[0] == 0 {
+		data = data[1:]
+	}
+	b.writeElement(id, data)
+}
+
+// writeUint64 writes an unsigned integer element with a fixed length of 8 bytes.
+// This is used for values that are updated later on, so that the encoded size doesn't change.
+func (b *ebmlBuffer) writeUint64(id uint32, value uint64) {
+	b.writeElement(id, binary.BigEndian.AppendUint64(nil, value))
+}
+
+// writeFloat writes a 64-bit floating point element.
+func (b *ebmlBuffer) writeFloat(id uint32, value float64) {
+	b.writeElement(id, binary.BigEndian.AppendUint64(nil, math.Float64bits(value)))
+}
+
+// writeString writes a string element.
+func (b *ebmlBuffer) writeString(id uint32, value string) {
+	b.writeElement(id, []byte(value))
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mkv
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// Remux writes all video and audio frames of the given MXV reader as Matroska file into ws.
+//
+// The JPEG and audio data is copied as is, there is no transcoding.
+// Consecutive video frames that reference the same MXJVVF64 chunk are stored once, as a single block with a longer duration.
+// Only such runs of repeated frames are deduplicated: Matroska blocks can't reference the data of other blocks,
+// so a MXJVVF64 chunk that is referenced again after other frames is stored again.
+// Audio frames are interleaved with the video frames by their timestamp.
+func Remux(ws io.WriteSeeker, r *mxv.Reader) error {
+	if err := r.PrepareLookupTable(); err != nil {
+		return fmt.Errorf("failed to prepare lookup table: %w", err)
+	}
+
+	w, err := NewWriter(ws, r.Info)
+	if err != nil {
+		return fmt.Errorf("failed to create Matroska writer: %w", err)
+	}
+
+	var vftes []mxriff64.Chunk32VFTEData
+	for _, vfte := range r.VideoFrames() {
+		vftes = append(vftes, vfte)
+	}
+
+	var audioStartSamples []uint64
+	for _, afte := range r.AudioFrames() {
+		audioStartSamples = append(audioStartSamples, afte.StartSample)
+	}
+
+	audioFrame := 0 // The next audio frame to be written.
+	writeAudioFramesUntil := func(sample uint64) error {
+		for ; audioFrame < len(audioStartSamples) && audioStartSamples[audioFrame] <= sample; audioFrame++ {
+			frameReader, startSample, samples, err := r.AudioFrameData(audioFrame)
+			if err != nil {
+				return fmt.Errorf("failed to get audio data stream of frame %d: %w", audioFrame, err)
+			}
+			if err := w.WriteAudioFrame(frameReader, startSample, samples); err != nil {
+				return fmt.Errorf("failed to write audio frame %d: %w", audioFrame, err)
+			}
+		}
+		return nil
+	}
+
+	for frame := 0; frame < len(vftes); {
+		// Count how often this frame is repeated.
+		frames := 1
+		for frame+frames < len(vftes) && vftes[frame+frames].VideoFrameChunkOffset == vftes[frame].VideoFrameChunkOffset {
+			frames++
+		}
+
+		// Write all audio frames that start before or at the same time as this video frame.
+		if r.Info.HasAudio {
+			startSample, _ := r.Info.VideoFrameTimestamp(uint64(frame), uint64(r.Info.AudioSampleRate))
+			if err := writeAudioFramesUntil(startSample); err != nil {
+				return err
+			}
+		}
+
+		frameReader, err := r.VideoFrameData(frame)
+		if err != nil {
+			return fmt.Errorf("failed to get video data stream of frame %d: %w", frame, err)
+		}
+		if err := w.WriteVideoFrame(frameReader, frames); err != nil {
+			return fmt.Errorf("failed to write video frame %d: %w", frame, err)
+		}
+
+		frame += frames
+	}
+
+	// Write any remaining audio frames.
+	if r.Info.HasAudio {
+		if err := writeAudioFramesUntil(math.MaxUint64); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish Matroska file: %w", err)
+	}
+
+	return nil
+}
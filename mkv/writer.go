@@ -0,0 +1,470 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package mkv implements a Matroska muxer that writes MJPEG video and PCM audio from MXV files.
+//
+// Video timestamps are derived from the frame number and the framerate, audio timestamps from the start sample of each audio frame.
+// Therefore, the timestamps don't drift, no matter how long the video is.
+package mkv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// The duration of a timestamp unit in nanoseconds.
+const timestampScale = 1000000
+
+// The targeted duration of a cluster in timestamp units.
+const clusterDuration = 1000
+
+// Track numbers.
+const (
+	trackVideo = 1
+	trackAudio = 2
+)
+
+// The application name that is written into the segment info.
+const appName = "mxv-demuxer"
+
+type cuePoint struct {
+	timestamp       int64
+	clusterPosition int64 // Position of the cluster relative to the segment data.
+}
+
+// Writer creates Matroska files with MJPEG video and uncompressed audio.
+//
+// If any method returns an error, the resulting file is most likely invalid.
+type Writer struct {
+	ws  io.WriteSeeker
+	pos int64 // Current file offset.
+
+	info          mxv.Info
+	audioCodecID  string
+	audioUnsigned bool // The audio data is unsigned 8-bit PCM, which has to be converted to signed PCM.
+
+	segmentOffset    int64 // File offset of the segment data.
+	clusterOffset    int64 // File offset of the current cluster data, or 0 if there is no open cluster.
+	clusterTimestamp int64
+	cuesPosition     int64 // Position of the cues relative to the segment data, or 0 if they are not written yet.
+	cuePoints        []cuePoint
+
+	videoFrames uint64 // Number of frame periods that are covered by the written video frames.
+	duration    int64  // End timestamp of the last written block.
+
+	closed bool
+}
+
+// NewWriter creates a new Matroska writer that writes into the given io.WriteSeeker.
+//
+// The track headers are filled from the given MXV info.
+// Close has to be called to finish the file.
+func NewWriter(ws io.WriteSeeker, info mxv.Info) (*Writer, error) {
+	if info.FrameWidth == 0 || info.FrameHeight == 0 {
+		return nil, fmt.Errorf("invalid frame size %dx%d", info.FrameWidth, info.FrameHeight)
+	}
+	if info.Framerate <= 0 {
+		return nil, fmt.Errorf("invalid framerate %v", info.Framerate)
+	}
+
+	w := &Writer{
+		ws:   ws,
+		info: info,
+	}
+
+	if info.HasAudio {
+		if info.AudioBytesPerSample == 0 || info.AudioSampleRate == 0 {
+			return nil, fmt.Errorf("invalid audio format: %d bytes per sample, %d samples/s", info.AudioBytesPerSample, info.AudioSampleRate)
+		}
+		switch info.AudioFormat {
+		case mxriff64.AudioFormatPCM:
+			w.audioCodecID = "A_PCM/INT/LIT"
+			// 8-bit PCM is unsigned, but Matroska only defines signed integer PCM.
+			w.audioUnsigned = info.AudioChannelBitDepth <= 8
+		case mxriff64.AudioFormatIEEEFloat:
+			w.audioCodecID = "A_PCM/FLOAT/IEEE"
+		default:
+			return nil, fmt.Errorf("unsupported audio format %v", info.AudioFormat)
+		}
+	}
+
+	var ebml ebmlBuffer
+	var ebmlHeader ebmlBuffer
+	ebmlHeader.writeUint(idEBMLVersion, 1)
+	ebmlHeader.writeUint(idEBMLReadVersion, 1)
+	ebmlHeader.writeUint(idEBMLMaxIDLength, 4)
+	ebmlHeader.writeUint(idEBMLMaxSizeLength, 8)
+	ebmlHeader.writeString(idDocType, "matroska")
+	ebmlHeader.writeUint(idDocTypeVersion, 4) // FlagInterlaced and FieldOrder were added in version 4.
+	ebmlHeader.writeUint(idDocTypeReadVersion, 2)
+	ebml.writeMaster(idEBML, &ebmlHeader)
+	if err := w.write(ebml.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to write EBML header: %w", err)
+	}
+
+	var err error
+	if w.segmentOffset, err = w.startElement(idSegment); err != nil {
+		return nil, fmt.Errorf("failed to write segment: %w", err)
+	}
+
+	// Write the headers with preliminary values, they will be updated by Close.
+	if err := w.write(w.headers()); err != nil {
+		return nil, fmt.Errorf("failed to write headers: %w", err)
+	}
+
+	return w, nil
+}
+
+// write writes the given data at the current file offset.
+func (w *Writer) write(data []byte) error {
+	n, err := w.ws.Write(data)
+	w.pos += int64(n)
+	return err
+}
+
+// writeAt writes the given data at the given file offset, and restores the current file offset afterwards.
+func (w *Writer) writeAt(offset int64, data []byte) error {
+	if wa, ok := w.ws.(io.WriterAt); ok {
+		_, err := wa.WriteAt(data, offset)
+		return err
+	}
+
+	if _, err := w.ws.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := w.ws.Write(data); err != nil {
+		return err
+	}
+	if _, err := w.ws.Seek(w.pos, io.SeekStart); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// startElement writes an element header with the given ID and an unknown size, and returns the file offset of the element data.
+// The size will be updated by endElement.
+func (w *Writer) startElement(id uint32) (int64, error) {
+	if err := w.write(encodeID(id)); err != nil {
+		return 0, err
+	}
+	if err := w.write(unknownSize[:]); err != nil {
+		return 0, err
+	}
+
+	return w.pos, nil
+}
+
+// endElement updates the size of the element whose data starts at the given file offset.
+func (w *Writer) endElement(dataOffset int64) error {
+	if err := w.writeAt(dataOffset-int64(len(unknownSize)), encodeSizeWidth(uint64(w.pos-dataOffset), len(unknownSize))); err != nil {
+		return fmt.Errorf("failed to update element size: %w", err)
+	}
+
+	return nil
+}
+
+// headers encodes the seek head, segment info and tracks elements.
+// The size of the encoded headers is independent of the number of written frames.
+func (w *Writer) headers() []byte {
+	info := w.info
+
+	var segmentInfo, segmentInfoContent ebmlBuffer
+	segmentInfoContent.writeUint(idTimestampScale, timestampScale)
+	segmentInfoContent.writeFloat(idDuration, float64(w.duration))
+	segmentInfoContent.writeString(idMuxingApp, appName)
+	segmentInfoContent.writeString(idWritingApp, appName)
+	segmentInfo.writeMaster(idInfo, &segmentInfoContent)
+
+	var tracks, tracksContent, trackEntry, video ebmlBuffer
+	displayWidth, displayHeight := uint64(info.FrameWidth), uint64(info.FrameHeight)
+	if info.AspectRatio > 0 {
+		displayWidth = uint64(math.Round(float64(info.FrameHeight) * info.AspectRatio))
+	}
+	video.writeUint(idPixelWidth, uint64(info.FrameWidth))
+	video.writeUint(idPixelHeight, uint64(info.FrameHeight))
+	video.writeUint(idDisplayWidth, displayWidth)
+	video.writeUint(idDisplayHeight, displayHeight)
+	switch {
+	case !info.Interlaced:
+		video.writeUint(idFlagInterlaced, 2) // Progressive.
+	case info.FieldOrder == mxriff64.FieldOrderBottomFirst:
+		video.writeUint(idFlagInterlaced, 1)
+		video.writeUint(idFieldOrder, 6) // Bottom field displayed first.
+	default:
+		video.writeUint(idFlagInterlaced, 1)
+		video.writeUint(idFieldOrder, 1) // Top field displayed first.
+	}
+	trackEntry.writeUint(idTrackNumber, trackVideo)
+	trackEntry.writeUint(idTrackUID, trackVideo)
+	trackEntry.writeUint(idTrackType, 1)
+	trackEntry.writeUint(idFlagLacing, 0)
+	framerateNum, framerateDen := info.FramerateFraction()
+	trackEntry.writeUint(idDefaultDuration, uint64(math.Round(1e9*float64(framerateDen)/float64(framerateNum))))
+	trackEntry.writeString(idCodecID, "V_MJPEG")
+	trackEntry.writeMaster(idVideo, &video)
+	tracksContent.writeMaster(idTrackEntry, &trackEntry)
+
+	if info.HasAudio {
+		var trackEntry, audio ebmlBuffer
+		audio.writeFloat(idSamplingFrequency, float64(info.AudioSampleRate))
+		audio.writeUint(idChannels, uint64(info.AudioChannels))
+		audio.writeUint(idBitDepth, uint64(info.AudioChannelBitDepth))
+		trackEntry.writeUint(idTrackNumber, trackAudio)
+		trackEntry.writeUint(idTrackUID, trackAudio)
+		trackEntry.writeUint(idTrackType, 2)
+		trackEntry.writeUint(idFlagLacing, 0)
+		trackEntry.writeString(idCodecID, w.audioCodecID)
+		trackEntry.writeMaster(idAudio, &audio)
+		tracksContent.writeMaster(idTrackEntry, &trackEntry)
+	}
+	tracks.writeMaster(idTracks, &tracksContent)
+
+	// The seek head has a fixed size, so its size can be determined before the positions are known.
+	seekHead := func(infoPosition, tracksPosition, cuesPosition int64) *ebmlBuffer {
+		var seekHead, seekHeadContent ebmlBuffer
+		for _, s := range []struct {
+			id       uint32
+			position int64
+		}{{idInfo, infoPosition}, {idTracks, tracksPosition}, {idCues, cuesPosition}} {
+			var seek ebmlBuffer
+			seek.writeElement(idSeekID, encodeID(s.id))
+			seek.writeUint64(idSeekPosition, uint64(s.position))
+			seekHeadContent.writeMaster(idSeek, &seek)
+		}
+		seekHead.writeMaster(idSeekHead, &seekHeadContent)
+		return &seekHead
+	}
+	seekHeadSize := int64(seekHead(0, 0, 0).Len())
+
+	result := seekHead(seekHeadSize, seekHeadSize+int64(segmentInfo.Len()), w.cuesPosition)
+	result.Write(segmentInfo.Bytes())
+	result.Write(tracks.Bytes())
+
+	return result.Bytes()
+}
+
+// videoTimestamp returns the timestamp of the given video frame in timestamp units.
+func (w *Writer) videoTimestamp(frame uint64) int64 {
+	pts, _ := w.info.VideoFrameTimestamp(frame, 1e9/timestampScale)
+	return int64(pts)
+}
+
+// audioTimestamp returns the timestamp of the given audio sample in timestamp units.
+func (w *Writer) audioTimestamp(sample uint64) int64 {
+	return int64(math.Round(float64(sample) * 1e9 / timestampScale / float64(w.info.AudioSampleRate)))
+}
+
+// startCluster finishes the current cluster, and starts a new one with the given timestamp.
+func (w *Writer) startCluster(timestamp int64) error {
+	if err := w.endCluster(); err != nil {
+		return err
+	}
+
+	clusterOffset, err := w.startElement(idCluster)
+	if err != nil {
+		return fmt.Errorf("failed to write cluster: %w", err)
+	}
+
+	var content ebmlBuffer
+	content.writeUint(idTimestamp, uint64(timestamp))
+	if err := w.write(content.Bytes()); err != nil {
+		return fmt.Errorf("failed to write cluster timestamp: %w", err)
+	}
+
+	w.clusterOffset, w.clusterTimestamp = clusterOffset, timestamp
+
+	return nil
+}
+
+// endCluster finishes the current cluster, if there is any.
+func (w *Writer) endCluster() error {
+	if w.clusterOffset == 0 {
+		return nil
+	}
+
+	if err := w.endElement(w.clusterOffset); err != nil {
+		return fmt.Errorf("failed to finish cluster: %w", err)
+	}
+	w.clusterOffset = 0
+
+	return nil
+}
+
+// writeBlock writes a block of the given track into the current cluster.
+// The data is copied from r.
+//
+// If duration is 0, a SimpleBlock is written.
+// Otherwise, a BlockGroup that contains the block and its duration is written.
+func (w *Writer) writeBlock(track uint8, timestamp, duration int64, r io.Reader) error {
+	if w.closed {
+		return fmt.Errorf("the writer is already closed")
+	}
+	if timestamp < 0 {
+		return fmt.Errorf("negative timestamp %d", timestamp)
+	}
+
+	// Start a new cluster for every video frame after the cluster duration, or whenever the relative timestamp would overflow.
+	relativeTimestamp := timestamp - w.clusterTimestamp
+	if w.clusterOffset == 0 || (track == trackVideo && relativeTimestamp >= clusterDuration) || relativeTimestamp < math.MinInt16 || relativeTimestamp > math.MaxInt16 {
+		if err := w.startCluster(timestamp); err != nil {
+			return err
+		}
+		relativeTimestamp = 0
+
+		if track == trackVideo {
+			w.cuePoints = append(w.cuePoints, cuePoint{timestamp: timestamp, clusterPosition: w.clusterOffset - int64(len(unknownSize)) - int64(len(encodeID(idCluster))) - w.segmentOffset})
+		}
+	}
+
+	var groupOffset int64
+	blockID, flags := uint32(idSimpleBlock), uint8(0x80) // SimpleBlocks are marked as keyframes, as every JPEG and PCM frame is one.
+	if duration != 0 {
+		var err error
+		if groupOffset, err = w.startElement(idBlockGroup); err != nil {
+			return fmt.Errorf("failed to write block group: %w", err)
+		}
+		blockID, flags = idBlock, 0x00
+	}
+
+	blockOffset, err := w.startElement(blockID)
+	if err != nil {
+		return fmt.Errorf("failed to write block: %w", err)
+	}
+	header := binary.BigEndian.AppendUint16(encodeSize(uint64(track)), uint16(int16(relativeTimestamp)))
+	if err := w.write(append(header, flags)); err != nil {
+		return fmt.Errorf("failed to write block header: %w", err)
+	}
+	n, err := io.Copy(w.ws, r)
+	w.pos += n
+	if err != nil {
+		return fmt.Errorf("failed to copy block data: %w", err)
+	}
+	if err := w.endElement(blockOffset); err != nil {
+		return fmt.Errorf("failed to finish block: %w", err)
+	}
+
+	if duration != 0 {
+		var content ebmlBuffer
+		content.writeUint(idBlockDuration, uint64(duration))
+		if err := w.write(content.Bytes()); err != nil {
+			return fmt.Errorf("failed to write block duration: %w", err)
+		}
+		if err := w.endElement(groupOffset); err != nil {
+			return fmt.Errorf("failed to finish block group: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteVideoFrame adds a new video frame to the file.
+// The raw JPEG data is copied from the given io.Reader.
+//
+// The frame is shown for the given number of frame periods.
+// This can be used to store consecutive repeated video frames only once.
+func (w *Writer) WriteVideoFrame(r io.Reader, frames int) error {
+	if frames < 1 {
+		return fmt.Errorf("invalid number of frame periods %d", frames)
+	}
+
+	timestamp := w.videoTimestamp(w.videoFrames)
+	endTimestamp := w.videoTimestamp(w.videoFrames + uint64(frames))
+
+	// Single frames use the track's default duration.
+	var duration int64
+	if frames > 1 {
+		duration = endTimestamp - timestamp
+	}
+
+	if err := w.writeBlock(trackVideo, timestamp, duration, r); err != nil {
+		return err
+	}
+
+	w.videoFrames += uint64(frames)
+	w.duration = max(w.duration, endTimestamp)
+
+	return nil
+}
+
+// WriteAudioFrame adds the given number of audio samples to the file.
+// The raw audio data is copied from the given io.Reader, its encoding has to match the audio format of the MXV info.
+//
+// The timestamp of the block is derived from startSample.
+// Unsigned 8-bit PCM is converted to signed PCM, as Matroska doesn't support unsigned PCM.
+func (w *Writer) WriteAudioFrame(r io.Reader, startSample uint64, samples uint32) error {
+	if !w.info.HasAudio {
+		return fmt.Errorf("the file is written without audio")
+	}
+
+	r = io.LimitReader(r, int64(samples)*int64(w.info.AudioBytesPerSample))
+	if w.audioUnsigned {
+		r = signedPCM{r}
+	}
+	if err := w.writeBlock(trackAudio, w.audioTimestamp(startSample), 0, r); err != nil {
+		return err
+	}
+
+	w.duration = max(w.duration, w.audioTimestamp(startSample+uint64(samples)))
+
+	return nil
+}
+
+// Close writes the cues and updates the headers.
+// This doesn't close the underlying io.WriteSeeker.
+func (w *Writer) Close() error {
+	if w.closed {
+		return fmt.Errorf("the writer is already closed")
+	}
+	w.closed = true
+
+	if err := w.endCluster(); err != nil {
+		return err
+	}
+
+	var cues, cuesContent ebmlBuffer
+	for _, cp := range w.cuePoints {
+		var cuePoint, trackPositions ebmlBuffer
+		trackPositions.writeUint(idCueTrack, trackVideo)
+		trackPositions.writeUint(idCueClusterPosition, uint64(cp.clusterPosition))
+		cuePoint.writeUint(idCueTime, uint64(cp.timestamp))
+		cuePoint.writeMaster(idCueTrackPositions, &trackPositions)
+		cuesContent.writeMaster(idCuePoint, &cuePoint)
+	}
+	cues.writeMaster(idCues, &cuesContent)
+	w.cuesPosition = w.pos - w.segmentOffset
+	if err := w.write(cues.Bytes()); err != nil {
+		return fmt.Errorf("failed to write cues: %w", err)
+	}
+
+	if err := w.endElement(w.segmentOffset); err != nil {
+		return fmt.Errorf("failed to finish segment: %w", err)
+	}
+
+	// The headers directly follow the segment header.
+	if err := w.writeAt(w.segmentOffset, w.headers()); err != nil {
+		return fmt.Errorf("failed to update headers: %w", err)
+	}
+
+	return nil
+}
+
+// signedPCM converts unsigned 8-bit PCM data into signed 8-bit PCM data.
+type signedPCM struct {
+	r io.Reader
+}
+
+func (s signedPCM) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	for i := range p[:n] {
+		p[i] ^= 0x80
+	}
+	return n, err
+}
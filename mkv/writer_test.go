@@ -0,0 +1,366 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mkv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// masterIDs contains all element IDs that are parsed as master elements.
+var masterIDs = map[uint32]bool{
+	idEBML: true, idSegment: true, idSeekHead: true, idSeek: true, idInfo: true, idTracks: true, idTrackEntry: true, idVideo: true,
+	idAudio: true, idCluster: true, idBlockGroup: true, idCues: true, idCuePoint: true, idCueTrackPositions: true,
+}
+
+// ebmlElement is a parsed EBML element.
+type ebmlElement struct {
+	id       uint32
+	offset   int64 // File offset of the element data.
+	data     []byte
+	children []ebmlElement
+}
+
+// readVint reads a variable length integer from data, and returns its value including the length marker, and its length.
+func readVint(t *testing.T, data []byte) (uint64, int) {
+	if len(data) == 0 || data[0] == 0 {
+		t.Fatalf("Invalid variable length integer.")
+	}
+	length := bits.LeadingZeros8(data[0]) + 1
+	if len(data) < length {
+		t.Fatalf("Truncated variable length integer.")
+	}
+	var value uint64
+	for _, b := range data[:length] {
+		value = value<<8 | uint64(b)
+	}
+	return value, length
+}
+
+// parseEBML parses all elements in data, which starts at the given file offset.
+func parseEBML(t *testing.T, data []byte, offset int64) []ebmlElement {
+	var elements []ebmlElement
+	for len(data) > 0 {
+		id, idLength := readVint(t, data)
+		size, sizeLength := readVint(t, data[idLength:])
+		size &^= 1 << (7 * sizeLength) // Remove length marker.
+		headerLength := int64(idLength + sizeLength)
+		if uint64(len(data)) < uint64(headerLength)+size {
+			t.Fatalf("Element %X at offset %d goes beyond its parent.", id, offset)
+		}
+
+		e := ebmlElement{id: uint32(id), offset: offset + headerLength, data: data[headerLength : headerLength+int64(size)]}
+		if masterIDs[e.id] {
+			e.children = parseEBML(t, e.data, e.offset)
+		}
+		elements = append(elements, e)
+
+		data, offset = data[headerLength+int64(size):], offset+headerLength+int64(size)
+	}
+	return elements
+}
+
+// find returns the first child element with the given ID.
+func (e ebmlElement) find(t *testing.T, id uint32) ebmlElement {
+	for _, child := range e.children {
+		if child.id == id {
+			return child
+		}
+	}
+	t.Fatalf("Couldn't find element %X in %X.", id, e.id)
+	return ebmlElement{}
+}
+
+// uint returns the data of the element as unsigned integer.
+func (e ebmlElement) uint() uint64 {
+	var value uint64
+	for _, b := range e.data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// block is a parsed (Simple)Block with its absolute timestamp.
+type block struct {
+	track     uint64
+	timestamp int64
+	duration  int64 // Only set for blocks in a BlockGroup.
+	data      []byte
+}
+
+// remuxAndParse remuxes the given MXV reader, and returns the segment element and all blocks.
+func remuxAndParse(t *testing.T, r *mxv.Reader) (ebmlElement, []block) {
+	filename := filepath.Join(t.TempDir(), "remuxed.mkv")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	if err := Remux(f, r); err != nil {
+		t.Fatalf("Failed to remux: %v.", err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+	elements := parseEBML(t, data, 0)
+	if len(elements) != 2 || elements[0].id != idEBML || elements[1].id != idSegment {
+		t.Fatalf("Expected an EBML header followed by a segment.")
+	}
+	if got := elements[0].find(t, idDocTypeVersion).uint(); got != 4 {
+		t.Errorf("Unexpected DocTypeVersion. Got %d, want %d.", got, 4)
+	}
+	segment := elements[1]
+
+	parseBlock := func(e ebmlElement, clusterTimestamp int64) block {
+		track, length := readVint(t, e.data)
+		track &^= 1 << (7 * length)
+		return block{
+			track:     track,
+			timestamp: clusterTimestamp + int64(int16(binary.BigEndian.Uint16(e.data[length:]))),
+			data:      e.data[length+3:],
+		}
+	}
+
+	var blocks []block
+	for _, cluster := range segment.children {
+		if cluster.id != idCluster {
+			continue
+		}
+		clusterTimestamp := int64(cluster.find(t, idTimestamp).uint())
+		for _, e := range cluster.children {
+			switch e.id {
+			case idSimpleBlock:
+				blocks = append(blocks, parseBlock(e, clusterTimestamp))
+			case idBlockGroup:
+				b := parseBlock(e.find(t, idBlock), clusterTimestamp)
+				b.duration = int64(e.find(t, idBlockDuration).uint())
+				blocks = append(blocks, b)
+			}
+		}
+	}
+
+	// Check that the seek head points to the right elements.
+	for _, seek := range segment.find(t, idSeekHead).children {
+		position := segment.offset + int64(seek.find(t, idSeekPosition).uint())
+		id, _ := readVint(t, data[position:])
+		if want := seek.find(t, idSeekID).uint(); id != want {
+			t.Errorf("Seek entry points to element %X, want %X.", id, want)
+		}
+	}
+
+	// Check that all cue points point to clusters.
+	for _, cuePoint := range segment.find(t, idCues).children {
+		position := segment.offset + int64(cuePoint.find(t, idCueTrackPositions).find(t, idCueClusterPosition).uint())
+		if id, _ := readVint(t, data[position:]); id != idCluster {
+			t.Errorf("Cue point points to element %X, want cluster.", id)
+		}
+	}
+
+	return segment, blocks
+}
+
+func TestRemux(t *testing.T) {
+	src, err := os.Open(filepath.Join("..", "example-files", "29.97p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer src.Close()
+
+	mxvReader, err := mxv.NewReader(src)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	segment, blocks := remuxAndParse(t, mxvReader)
+
+	video := segment.find(t, idTracks).find(t, idTrackEntry).find(t, idVideo)
+	if got := video.find(t, idDisplayWidth).uint(); got != 1920 {
+		t.Errorf("Unexpected display width. Got %d, want %d.", got, 1920)
+	}
+	if got := video.find(t, idFlagInterlaced).uint(); got != 2 {
+		t.Errorf("Unexpected interlacing flag. Got %d, want %d.", got, 2)
+	}
+
+	var videoFrame int
+	var audioData []byte
+	for _, b := range blocks {
+		switch b.track {
+		case trackVideo:
+			r, err := mxvReader.VideoFrameData(videoFrame)
+			if err != nil {
+				t.Fatalf("Failed to get video data stream: %v.", err)
+			}
+			want, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Failed to read video data stream: %v.", err)
+			}
+			if !bytes.Equal(b.data, want) {
+				t.Errorf("Video frame %d differs from source. Got %d bytes, want %d bytes.", videoFrame, len(b.data), len(want))
+			}
+			if want := int64(math.Round(float64(videoFrame) * 1000 / 29.97)); b.timestamp != want {
+				t.Errorf("Video frame %d has wrong timestamp. Got %d, want %d.", videoFrame, b.timestamp, want)
+			}
+			videoFrame++
+		case trackAudio:
+			audioData = append(audioData, b.data...)
+		}
+	}
+
+	if videoFrame != int(mxvReader.Info.VideoFrames) {
+		t.Errorf("Unexpected number of video frames. Got %d, want %d.", videoFrame, mxvReader.Info.VideoFrames)
+	}
+	if got, want := uint64(len(audioData)), mxvReader.Info.AudioSamples*uint64(mxvReader.Info.AudioBytesPerSample); got != want {
+		t.Errorf("Unexpected audio data length. Got %d bytes, want %d bytes.", got, want)
+	}
+}
+
+// TestRemuxRepeatedFrames checks that consecutive repeated video frames are stored only once.
+func TestRemuxRepeatedFrames(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "synthetic.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	mxvWriter, err := mxv.NewWriter(f, mxv.Info{
+		ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 16, FrameHeight: 8, Framerate: 25, AspectRatio: 4, Interlaced: true,
+		HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioChannelBitDepth: 16,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MXV writer: %v.", err)
+	}
+
+	frames := [][]byte{{0xFF, 0xD8, 1, 0xFF, 0xD9}, {0xFF, 0xD8, 2, 2, 0xFF, 0xD9}}
+	if err := mxvWriter.WriteVideoFrame(bytes.NewReader(frames[0])); err != nil {
+		t.Fatalf("Failed to write video frame: %v.", err)
+	}
+	for range 2 {
+		if err := mxvWriter.RepeatVideoFrame(0); err != nil {
+			t.Fatalf("Failed to repeat video frame: %v.", err)
+		}
+	}
+	if err := mxvWriter.WriteVideoFrame(bytes.NewReader(frames[1])); err != nil {
+		t.Fatalf("Failed to write video frame: %v.", err)
+	}
+	for range 4 {
+		if err := mxvWriter.WriteAudioFrame(bytes.NewReader(make([]byte, 1920*4)), 1920); err != nil {
+			t.Fatalf("Failed to write audio frame: %v.", err)
+		}
+	}
+	if err := mxvWriter.Close(); err != nil {
+		t.Fatalf("Failed to close MXV writer: %v.", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v.", err)
+	}
+	mxvReader, err := mxv.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	segment, blocks := remuxAndParse(t, mxvReader)
+
+	video := segment.find(t, idTracks).find(t, idTrackEntry).find(t, idVideo)
+	if got := video.find(t, idDisplayWidth).uint(); got != 32 {
+		t.Errorf("Unexpected display width. Got %d, want %d.", got, 32)
+	}
+	if got, want := [2]uint64{video.find(t, idFlagInterlaced).uint(), video.find(t, idFieldOrder).uint()}, [2]uint64{1, 1}; got != want {
+		t.Errorf("Unexpected interlacing flag and field order. Got %v, want %v.", got, want)
+	}
+
+	var videoBlocks []block
+	var audioTimestamps []int64
+	for _, b := range blocks {
+		switch b.track {
+		case trackVideo:
+			videoBlocks = append(videoBlocks, b)
+		case trackAudio:
+			audioTimestamps = append(audioTimestamps, b.timestamp)
+		}
+	}
+
+	wantVideoBlocks := []block{
+		{track: trackVideo, timestamp: 0, duration: 120, data: frames[0]},
+		{track: trackVideo, timestamp: 120, data: frames[1]},
+	}
+	if len(videoBlocks) != len(wantVideoBlocks) {
+		t.Fatalf("Unexpected number of video blocks. Got %d, want %d.", len(videoBlocks), len(wantVideoBlocks))
+	}
+	for i, want := range wantVideoBlocks {
+		got := videoBlocks[i]
+		if got.timestamp != want.timestamp || got.duration != want.duration || !bytes.Equal(got.data, want.data) {
+			t.Errorf("Video block %d differs. Got %+v, want %+v.", i, got, want)
+		}
+	}
+
+	if want := []int64{0, 40, 80, 120}; !slices.Equal(audioTimestamps, want) {
+		t.Errorf("Unexpected audio timestamps. Got %v, want %v.", audioTimestamps, want)
+	}
+
+	if got := math.Float64frombits(binary.BigEndian.Uint64(segment.find(t, idInfo).find(t, idDuration).data)); got != 160 {
+		t.Errorf("Unexpected duration. Got %v, want %v.", got, 160)
+	}
+}
+
+// TestRemux8BitAudio checks that unsigned 8-bit PCM is converted to signed PCM.
+func TestRemux8BitAudio(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "synthetic.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	mxvWriter, err := mxv.NewWriter(f, mxv.Info{
+		ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 16, FrameHeight: 8, Framerate: 25,
+		HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 1, AudioSampleRate: 8000, AudioChannelBitDepth: 8,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MXV writer: %v.", err)
+	}
+	if err := mxvWriter.WriteVideoFrame(bytes.NewReader([]byte{0xFF, 0xD8, 0xFF, 0xD9})); err != nil {
+		t.Fatalf("Failed to write video frame: %v.", err)
+	}
+	if err := mxvWriter.WriteAudioFrame(bytes.NewReader([]byte{0x80, 0xFF, 0x00, 0x81}), 4); err != nil {
+		t.Fatalf("Failed to write audio frame: %v.", err)
+	}
+	if err := mxvWriter.Close(); err != nil {
+		t.Fatalf("Failed to close MXV writer: %v.", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v.", err)
+	}
+	mxvReader, err := mxv.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	_, blocks := remuxAndParse(t, mxvReader)
+
+	var audioData []byte
+	for _, b := range blocks {
+		if b.track == trackAudio {
+			audioData = append(audioData, b.data...)
+		}
+	}
+	if want := []byte{0x00, 0x7F, 0x80, 0x01}; !bytes.Equal(audioData, want) {
+		t.Errorf("Unexpected audio data. Got %v, want %v.", audioData, want)
+	}
+}
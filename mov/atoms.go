@@ -0,0 +1,205 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mov
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// The identity matrix used in movie and track headers.
+var unityMatrix = [9]uint32{0x00010000, 0, 0, 0, 0x00010000, 0, 0, 0, 0x40000000}
+
+type movieHeader struct {
+	VersionFlags      uint32
+	CreationTime      uint32
+	ModificationTime  uint32
+	TimeScale         uint32
+	Duration          uint32
+	PreferredRate     uint32 // Fixed point 16.16.
+	PreferredVolume   uint16 // Fixed point 8.8.
+	Reserved          [10]byte
+	Matrix            [9]uint32
+	PreviewTime       uint32
+	PreviewDuration   uint32
+	PosterTime        uint32
+	SelectionTime     uint32
+	SelectionDuration uint32
+	CurrentTime       uint32
+	NextTrackID       uint32
+}
+
+type trackHeader struct {
+	VersionFlags     uint32
+	CreationTime     uint32
+	ModificationTime uint32
+	TrackID          uint32
+	Reserved1        uint32
+	Duration         uint32 // Duration in the time scale of the movie header.
+	Reserved2        [8]byte
+	Layer            uint16
+	AlternateGroup   uint16
+	Volume           uint16 // Fixed point 8.8.
+	Reserved3        uint16
+	Matrix           [9]uint32
+	Width            uint32 // Fixed point 16.16.
+	Height           uint32 // Fixed point 16.16.
+}
+
+type mediaHeader struct {
+	VersionFlags     uint32
+	CreationTime     uint32
+	ModificationTime uint32
+	TimeScale        uint32
+	Duration         uint32
+	Language         uint16
+	Quality          uint16
+}
+
+type handlerReference struct {
+	VersionFlags          uint32
+	ComponentType         [4]byte
+	ComponentSubtype      [4]byte
+	ComponentManufacturer uint32
+	ComponentFlags        uint32
+	ComponentFlagsMask    uint32
+}
+
+type videoMediaInformationHeader struct {
+	VersionFlags uint32
+	GraphicsMode uint16
+	OpColor      [3]uint16
+}
+
+type soundMediaInformationHeader struct {
+	VersionFlags uint32
+	Balance      uint16
+	Reserved     uint16
+}
+
+type sampleEntryHeader struct {
+	Reserved           [6]byte
+	DataReferenceIndex uint16
+}
+
+type videoSampleDescription struct {
+	sampleEntryHeader
+	Version              uint16
+	RevisionLevel        uint16
+	Vendor               uint32
+	TemporalQuality      uint32
+	SpatialQuality       uint32
+	Width                uint16
+	Height               uint16
+	HorizontalResolution uint32 // Fixed point 16.16.
+	VerticalResolution   uint32 // Fixed point 16.16.
+	DataSize             uint32
+	FrameCount           uint16
+	CompressorName       [32]byte // Pascal string.
+	Depth                uint16
+	ColorTableID         int16
+}
+
+// Version 0 sound sample description, used for 16-bit little endian PCM ("sowt").
+type soundSampleDescriptionV0 struct {
+	sampleEntryHeader
+	Version          uint16
+	RevisionLevel    uint16
+	Vendor           uint32
+	NumberOfChannels uint16
+	SampleSize       uint16
+	CompressionID    int16
+	PacketSize       uint16
+	SampleRate       uint32 // Fixed point 16.16.
+}
+
+// Version 2 sound sample description, used for any other PCM format ("lpcm").
+type soundSampleDescriptionV2 struct {
+	sampleEntryHeader
+	Version                       uint16
+	RevisionLevel                 uint16
+	Vendor                        uint32
+	Always3                       uint16
+	Always16                      uint16
+	AlwaysMinus2                  int16
+	Always0                       uint16
+	Always65536                   uint32
+	SizeOfStructOnly              uint32
+	AudioSampleRate               float64
+	NumAudioChannels              uint32
+	Always7F000000                uint32
+	ConstBitsPerChannel           uint32
+	FormatSpecificFlags           uint32
+	ConstBytesPerAudioPacket      uint32
+	ConstLPCMFramesPerAudioPacket uint32
+}
+
+// Format specific flags of the "lpcm" sample description.
+const (
+	lpcmFlagIsFloat         = 1 << 0
+	lpcmFlagIsBigEndian     = 1 << 1
+	lpcmFlagIsSignedInteger = 1 << 2
+	lpcmFlagIsPacked        = 1 << 3
+)
+
+// Field handling, as stored in the "fiel" atom.
+type fieldHandling struct {
+	Fields uint8 // 1 for progressive, 2 for interlaced video.
+	Detail uint8 // Field ordering. 0 for progressive video.
+}
+
+// Field ordering values for frames that contain two interleaved fields.
+const (
+	fieldDetailTopFirst    = 9
+	fieldDetailBottomFirst = 14
+)
+
+type pixelAspectRatio struct {
+	HorizontalSpacing uint32
+	VerticalSpacing   uint32
+}
+
+type timeToSampleEntry struct {
+	SampleCount    uint32
+	SampleDuration uint32
+}
+
+type sampleToChunkEntry struct {
+	FirstChunk          uint32
+	SamplesPerChunk     uint32
+	SampleDescriptionID uint32
+}
+
+// atomBuffer is used to encode atoms of the movie resource in memory.
+type atomBuffer struct {
+	bytes.Buffer
+}
+
+// writeAtom writes an atom of the given type that contains the given data.
+func (b *atomBuffer) writeAtom(atomType [4]byte, data ...any) {
+	var content atomBuffer
+	content.writeRaw(data...)
+
+	b.writeRaw(uint32(8+content.Len()), atomType, content.Bytes())
+}
+
+// writeContainer writes an atom of the given type that contains the given child atoms.
+func (b *atomBuffer) writeContainer(atomType [4]byte, children *atomBuffer) {
+	b.writeRaw(uint32(8+children.Len()), atomType, children.Bytes())
+}
+
+func (b *atomBuffer) writeRaw(data ...any) {
+	for _, d := range data {
+		binary.Write(b, binary.BigEndian, d) // Writing into a bytes.Buffer never fails for fixed size data.
+	}
+}
+
+// pascalString32 returns the given string as Pascal string with a fixed size of 32 bytes.
+func pascalString32(s string) [32]byte {
+	var result [32]byte
+	result[0] = byte(copy(result[1:], s))
+	return result
+}
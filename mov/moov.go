@@ -0,0 +1,205 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mov
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/internal/fraction"
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// The packed ISO 639-2 language code "und".
+const languageUndetermined = 0x55C4
+
+// movieResource encodes the moov atom with all tracks and sample tables.
+func (w *Writer) movieResource() ([]byte, error) {
+	info := w.info
+
+	// The movie uses the time scale of the video track.
+	videoDuration := w.video.duration
+	var audioDuration uint64
+	if info.HasAudio {
+		audioDuration = uint64(math.Round(float64(w.audio.duration) * float64(w.videoTimeScale) / float64(info.AudioSampleRate)))
+	}
+	if max(videoDuration, audioDuration, w.audio.duration) > math.MaxUint32 || w.audio.samples > math.MaxUint32 {
+		return nil, fmt.Errorf("the movie is too long")
+	}
+
+	var moov atomBuffer
+	nextTrackID := uint32(trackVideo + 1)
+	if info.HasAudio {
+		nextTrackID = trackAudio + 1
+	}
+	moov.writeAtom([4]byte{'m', 'v', 'h', 'd'}, movieHeader{
+		TimeScale:       w.videoTimeScale,
+		Duration:        uint32(max(videoDuration, audioDuration)),
+		PreferredRate:   0x00010000,
+		PreferredVolume: 0x0100,
+		Matrix:          unityMatrix,
+		NextTrackID:     nextTrackID,
+	})
+
+	// Video track.
+	displayWidth := float64(info.FrameWidth)
+	if info.AspectRatio > 0 {
+		displayWidth = float64(info.FrameHeight) * info.AspectRatio
+	}
+
+	var videoSampleEntry atomBuffer
+	videoSampleEntry.writeRaw(videoSampleDescription{
+		sampleEntryHeader:    sampleEntryHeader{DataReferenceIndex: 1},
+		SpatialQuality:       0x00000200, // codecNormalQuality.
+		Width:                uint16(info.FrameWidth),
+		Height:               uint16(info.FrameHeight),
+		HorizontalResolution: 72 << 16,
+		VerticalResolution:   72 << 16,
+		FrameCount:           1,
+		CompressorName:       pascalString32("Photo - JPEG"),
+		Depth:                24,
+		ColorTableID:         -1,
+	})
+	videoSampleEntry.writeAtom([4]byte{'f', 'i', 'e', 'l'}, w.fieldHandling())
+	parNum, parDen := uint64(1), uint64(1)
+	if info.AspectRatio > 0 {
+		parNum, parDen = fraction.Approximate(info.AspectRatio*float64(info.FrameHeight)/float64(info.FrameWidth), 1000)
+	}
+	videoSampleEntry.writeAtom([4]byte{'p', 'a', 's', 'p'}, pixelAspectRatio{HorizontalSpacing: uint32(parNum), VerticalSpacing: uint32(parDen)})
+
+	var videoTrack atomBuffer
+	videoTrack.writeAtom([4]byte{'t', 'k', 'h', 'd'}, trackHeader{
+		VersionFlags: 0x0000000F, // Enabled, in movie, in preview, in poster.
+		TrackID:      trackVideo,
+		Duration:     uint32(videoDuration),
+		Matrix:       unityMatrix,
+		Width:        uint32(math.Round(displayWidth * 65536)),
+		Height:       info.FrameHeight << 16,
+	})
+	videoTrack.writeMedia(
+		mediaHeader{TimeScale: w.videoTimeScale, Duration: uint32(w.video.duration), Language: languageUndetermined},
+		[4]byte{'v', 'i', 'd', 'e'}, "VideoHandler",
+		func(minf *atomBuffer) {
+			minf.writeAtom([4]byte{'v', 'm', 'h', 'd'}, videoMediaInformationHeader{VersionFlags: 1, GraphicsMode: 0x0040, OpColor: [3]uint16{0x8000, 0x8000, 0x8000}})
+		},
+		[4]byte{'j', 'p', 'e', 'g'}, &videoSampleEntry, &w.video, 0,
+	)
+	moov.writeContainer([4]byte{'t', 'r', 'a', 'k'}, &videoTrack)
+
+	// Audio track.
+	if info.HasAudio {
+		var audioSampleEntry atomBuffer
+		audioFormat := [4]byte{'l', 'p', 'c', 'm'}
+		if info.AudioFormat == mxriff64.AudioFormatPCM && info.AudioChannelBitDepth == 16 && info.AudioSampleRate <= math.MaxUint16 {
+			audioFormat = [4]byte{'s', 'o', 'w', 't'}
+			audioSampleEntry.writeRaw(soundSampleDescriptionV0{
+				sampleEntryHeader: sampleEntryHeader{DataReferenceIndex: 1},
+				NumberOfChannels:  info.AudioChannels,
+				SampleSize:        16,
+				SampleRate:        info.AudioSampleRate << 16,
+			})
+		} else {
+			var flags uint32
+			switch {
+			case info.AudioFormat == mxriff64.AudioFormatIEEEFloat:
+				flags |= lpcmFlagIsFloat
+			case info.AudioChannelBitDepth > 8: // 8-bit PCM is unsigned.
+				flags |= lpcmFlagIsSignedInteger
+			}
+			if info.AudioChannels > 0 && uint32(info.AudioBytesPerSample)*8 == info.AudioChannelBitDepth*uint32(info.AudioChannels) {
+				flags |= lpcmFlagIsPacked
+			}
+			audioSampleEntry.writeRaw(soundSampleDescriptionV2{
+				sampleEntryHeader:             sampleEntryHeader{DataReferenceIndex: 1},
+				Version:                       2,
+				Always3:                       3,
+				Always16:                      16,
+				AlwaysMinus2:                  -2,
+				Always65536:                   65536,
+				SizeOfStructOnly:              72,
+				AudioSampleRate:               float64(info.AudioSampleRate),
+				NumAudioChannels:              uint32(info.AudioChannels),
+				Always7F000000:                0x7F000000,
+				ConstBitsPerChannel:           info.AudioChannelBitDepth,
+				FormatSpecificFlags:           flags,
+				ConstBytesPerAudioPacket:      uint32(info.AudioBytesPerSample),
+				ConstLPCMFramesPerAudioPacket: 1,
+			})
+		}
+
+		var audioTrack atomBuffer
+		audioTrack.writeAtom([4]byte{'t', 'k', 'h', 'd'}, trackHeader{
+			VersionFlags:   0x0000000F, // Enabled, in movie, in preview, in poster.
+			TrackID:        trackAudio,
+			Duration:       uint32(audioDuration),
+			AlternateGroup: 1,
+			Volume:         0x0100,
+			Matrix:         unityMatrix,
+		})
+		audioTrack.writeMedia(
+			mediaHeader{TimeScale: info.AudioSampleRate, Duration: uint32(w.audio.duration), Language: languageUndetermined},
+			[4]byte{'s', 'o', 'u', 'n'}, "SoundHandler",
+			func(minf *atomBuffer) {
+				minf.writeAtom([4]byte{'s', 'm', 'h', 'd'}, soundMediaInformationHeader{})
+			},
+			audioFormat, &audioSampleEntry, &w.audio, uint32(info.AudioBytesPerSample),
+		)
+		moov.writeContainer([4]byte{'t', 'r', 'a', 'k'}, &audioTrack)
+	}
+
+	var result atomBuffer
+	result.writeContainer([4]byte{'m', 'o', 'o', 'v'}, &moov)
+
+	return result.Bytes(), nil
+}
+
+// fieldHandling returns the content of the fiel atom, derived from the interlacing of the MXV info.
+func (w *Writer) fieldHandling() fieldHandling {
+	switch {
+	case !w.info.Interlaced:
+		return fieldHandling{Fields: 1}
+	case w.info.FieldOrder == mxriff64.FieldOrderBottomFirst:
+		return fieldHandling{Fields: 2, Detail: fieldDetailBottomFirst}
+	default:
+		return fieldHandling{Fields: 2, Detail: fieldDetailTopFirst}
+	}
+}
+
+// writeMedia writes the mdia atom of a track.
+//
+// writeMediaHeader is called to write the media type specific information header into the minf atom.
+// If sampleSize is 0, the size of every sample is stored in the sample size table.
+func (b *atomBuffer) writeMedia(mdhd mediaHeader, handlerType [4]byte, handlerName string, writeMediaHeader func(minf *atomBuffer), format [4]byte, sampleEntry *atomBuffer, t *track, sampleSize uint32) {
+	var mdia, minf, dinf, dref, stbl, stsd atomBuffer
+
+	mdia.writeAtom([4]byte{'m', 'd', 'h', 'd'}, mdhd)
+	mdia.writeAtom([4]byte{'h', 'd', 'l', 'r'}, handlerReference{ComponentType: [4]byte{'m', 'h', 'l', 'r'}, ComponentSubtype: handlerType}, uint8(len(handlerName)), []byte(handlerName))
+
+	writeMediaHeader(&minf)
+	minf.writeAtom([4]byte{'h', 'd', 'l', 'r'}, handlerReference{ComponentType: [4]byte{'d', 'h', 'l', 'r'}, ComponentSubtype: [4]byte{'a', 'l', 'i', 's'}}, uint8(len("DataHandler")), []byte("DataHandler"))
+
+	// A single data reference to the file itself.
+	dref.writeRaw(uint32(0), uint32(1))
+	dref.writeAtom([4]byte{'a', 'l', 'i', 's'}, uint32(1))
+	dinf.writeContainer([4]byte{'d', 'r', 'e', 'f'}, &dref)
+	minf.writeContainer([4]byte{'d', 'i', 'n', 'f'}, &dinf)
+
+	stsd.writeRaw(uint32(0), uint32(1))
+	stsd.writeContainer(format, sampleEntry)
+	stbl.writeContainer([4]byte{'s', 't', 's', 'd'}, &stsd)
+	stbl.writeAtom([4]byte{'s', 't', 't', 's'}, uint32(0), uint32(len(t.timeToSample)), t.timeToSample)
+	stbl.writeAtom([4]byte{'s', 't', 's', 'c'}, uint32(0), uint32(len(t.sampleToChunk)), t.sampleToChunk)
+	if sampleSize == 0 {
+		stbl.writeAtom([4]byte{'s', 't', 's', 'z'}, uint32(0), uint32(0), uint32(len(t.sampleSizes)), t.sampleSizes)
+	} else {
+		stbl.writeAtom([4]byte{'s', 't', 's', 'z'}, uint32(0), sampleSize, uint32(t.samples))
+	}
+	stbl.writeAtom([4]byte{'c', 'o', '6', '4'}, uint32(0), uint32(len(t.chunkOffsets)), t.chunkOffsets)
+	minf.writeContainer([4]byte{'s', 't', 'b', 'l'}, &stbl)
+
+	mdia.writeContainer([4]byte{'m', 'i', 'n', 'f'}, &minf)
+	b.writeContainer([4]byte{'m', 'd', 'i', 'a'}, &mdia)
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mov
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// Remux writes all video and audio frames of the given MXV reader as QuickTime file into ws.
+//
+// The JPEG and audio data is copied as is, there is no transcoding.
+// Consecutive video frames that reference the same MXJVVF64 chunk are merged into a single sample with a longer duration.
+// Any other repeated video frame references the already written data, so every MXJVVF64 chunk is stored only once.
+// The audio is interleaved with the video frames it belongs to, and gaps between audio frames are filled with silence, see mxv.AudioStream.
+func Remux(ws io.WriteSeeker, r *mxv.Reader) error {
+	if err := r.PrepareLookupTable(); err != nil {
+		return fmt.Errorf("failed to prepare lookup table: %w", err)
+	}
+
+	w, err := NewWriter(ws, r.Info)
+	if err != nil {
+		return fmt.Errorf("failed to create QuickTime writer: %w", err)
+	}
+
+	var vftes []mxriff64.Chunk32VFTEData
+	for _, vfte := range r.VideoFrames() {
+		vftes = append(vftes, vfte)
+	}
+
+	var stream *mxv.AudioStream
+	if r.Info.HasAudio {
+		if stream, err = r.AudioStream(); err != nil {
+			return fmt.Errorf("failed to get audio stream: %w", err)
+		}
+	}
+
+	var audioSamples uint64 // The number of already written audio samples.
+	writeAudioUntil := func(sample uint64) error {
+		for end := min(sample, stream.Samples()); audioSamples < end; {
+			samples := uint32(min(end-audioSamples, math.MaxUint32))
+			if err := w.WriteAudioFrame(stream, samples); err != nil {
+				return fmt.Errorf("failed to write audio at sample %d: %w", audioSamples, err)
+			}
+			audioSamples += uint64(samples)
+		}
+		return nil
+	}
+
+	writtenSamples := map[int64]int{} // Maps MXJVVF64 chunk offsets to already written samples.
+	var sample int
+	for frame := 0; frame < len(vftes); sample++ {
+		// Count how often this frame is repeated.
+		frames := 1
+		for frame+frames < len(vftes) && vftes[frame+frames].VideoFrameChunkOffset == vftes[frame].VideoFrameChunkOffset {
+			frames++
+		}
+
+		if writtenSample, ok := writtenSamples[vftes[frame].VideoFrameChunkOffset]; ok {
+			if err := w.RepeatVideoFrame(writtenSample, frames); err != nil {
+				return fmt.Errorf("failed to repeat video frame %d: %w", frame, err)
+			}
+		} else {
+			frameReader, err := r.VideoFrameData(frame)
+			if err != nil {
+				return fmt.Errorf("failed to get video data stream of frame %d: %w", frame, err)
+			}
+			if err := w.WriteVideoFrame(frameReader, frames); err != nil {
+				return fmt.Errorf("failed to write video frame %d: %w", frame, err)
+			}
+			writtenSamples[vftes[frame].VideoFrameChunkOffset] = sample
+		}
+
+		frame += frames
+
+		// Write all audio samples until the end of this video frame.
+		if r.Info.HasAudio {
+			endSample, _ := r.Info.VideoFrameTimestamp(uint64(frame), uint64(r.Info.AudioSampleRate))
+			if err := writeAudioUntil(endSample); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Write any remaining audio samples.
+	if r.Info.HasAudio {
+		if err := writeAudioUntil(math.MaxUint64); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish QuickTime file: %w", err)
+	}
+
+	return nil
+}
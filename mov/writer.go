@@ -0,0 +1,256 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package mov implements a QuickTime muxer that writes JPEG video and PCM audio from MXV files.
+//
+// The media data is written into a single mdat atom with a 64-bit size, followed by the movie resource.
+// Chunk offsets are stored as 64-bit values, so there is no file size limit.
+package mov
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// Track IDs.
+const (
+	trackVideo = 1
+	trackAudio = 2
+)
+
+// track contains the sample tables of a single track.
+type track struct {
+	chunkOffsets  []uint64 // File offsets of all chunks.
+	sampleSizes   []uint32 // Sizes of all samples. Only used for video, as audio samples have a constant size.
+	timeToSample  []timeToSampleEntry
+	sampleToChunk []sampleToChunkEntry
+	duration      uint64 // Duration in units of the track's time scale.
+	samples       uint64 // Number of samples.
+}
+
+// addSamples adds the given number of samples with the given duration to the time-to-sample table.
+func (t *track) addSamples(count, duration uint32) {
+	if n := len(t.timeToSample); n > 0 && t.timeToSample[n-1].SampleDuration == duration {
+		t.timeToSample[n-1].SampleCount += count
+	} else {
+		t.timeToSample = append(t.timeToSample, timeToSampleEntry{SampleCount: count, SampleDuration: duration})
+	}
+	t.duration += uint64(count) * uint64(duration)
+	t.samples += uint64(count)
+}
+
+// addChunk adds a chunk at the given file offset that contains the given number of samples.
+func (t *track) addChunk(offset uint64, samples uint32) {
+	t.chunkOffsets = append(t.chunkOffsets, offset)
+	if n := len(t.sampleToChunk); n == 0 || t.sampleToChunk[n-1].SamplesPerChunk != samples {
+		t.sampleToChunk = append(t.sampleToChunk, sampleToChunkEntry{FirstChunk: uint32(len(t.chunkOffsets)), SamplesPerChunk: samples, SampleDescriptionID: 1})
+	}
+}
+
+// Writer creates QuickTime files with JPEG video and uncompressed audio.
+//
+// If any method returns an error, the resulting file is most likely invalid.
+type Writer struct {
+	ws  io.WriteSeeker
+	pos int64 // Current file offset.
+
+	info mxv.Info
+
+	videoTimeScale      uint32 // Number of time units per second. This is also used as the time scale of the movie.
+	videoSampleDuration uint32 // Duration of a single frame in time units.
+
+	mdatOffset int64 // File offset of the mdat atom.
+
+	video track
+	audio track
+
+	closed bool
+}
+
+// NewWriter creates a new QuickTime writer that writes into the given io.WriteSeeker.
+//
+// The sample descriptions are filled from the given MXV info.
+// Close has to be called to finish the file.
+func NewWriter(ws io.WriteSeeker, info mxv.Info) (*Writer, error) {
+	if info.FrameWidth == 0 || info.FrameHeight == 0 || info.FrameWidth > math.MaxUint16 || info.FrameHeight > math.MaxUint16 {
+		return nil, fmt.Errorf("invalid frame size %dx%d", info.FrameWidth, info.FrameHeight)
+	}
+	if info.Framerate <= 0 {
+		return nil, fmt.Errorf("invalid framerate %v", info.Framerate)
+	}
+	if info.HasAudio {
+		if info.AudioBytesPerSample == 0 || info.AudioSampleRate == 0 {
+			return nil, fmt.Errorf("invalid audio format: %d bytes per sample, %d samples/s", info.AudioBytesPerSample, info.AudioSampleRate)
+		}
+		switch info.AudioFormat {
+		case mxriff64.AudioFormatPCM, mxriff64.AudioFormatIEEEFloat:
+		default:
+			return nil, fmt.Errorf("unsupported audio format %v", info.AudioFormat)
+		}
+	}
+
+	timeScale, sampleDuration := info.FramerateFraction()
+
+	w := &Writer{
+		ws:                  ws,
+		info:                info,
+		videoTimeScale:      uint32(timeScale),
+		videoSampleDuration: uint32(sampleDuration),
+	}
+
+	var ftyp atomBuffer
+	ftyp.writeAtom([4]byte{'f', 't', 'y', 'p'}, [4]byte{'q', 't', ' ', ' '}, uint32(0x20050300), [4]byte{'q', 't', ' ', ' '})
+	if err := w.write(ftyp.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to write ftyp atom: %w", err)
+	}
+
+	// The mdat atom uses the extended 64-bit size field, which is updated by Close.
+	w.mdatOffset = w.pos
+	if err := w.write(binary.BigEndian.AppendUint64([]byte{0, 0, 0, 1, 'm', 'd', 'a', 't'}, 16)); err != nil {
+		return nil, fmt.Errorf("failed to write mdat atom: %w", err)
+	}
+
+	return w, nil
+}
+
+// write writes the given data at the current file offset.
+func (w *Writer) write(data []byte) error {
+	n, err := w.ws.Write(data)
+	w.pos += int64(n)
+	return err
+}
+
+// writeAt writes the given data at the given file offset, and restores the current file offset afterwards.
+func (w *Writer) writeAt(offset int64, data []byte) error {
+	if wa, ok := w.ws.(io.WriterAt); ok {
+		_, err := wa.WriteAt(data, offset)
+		return err
+	}
+
+	if _, err := w.ws.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := w.ws.Write(data); err != nil {
+		return err
+	}
+	if _, err := w.ws.Seek(w.pos, io.SeekStart); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// copyData copies all data from r into the mdat atom, and returns the file offset and size of the copied data.
+func (w *Writer) copyData(r io.Reader) (int64, int64, error) {
+	if w.closed {
+		return 0, 0, fmt.Errorf("the writer is already closed")
+	}
+
+	offset := w.pos
+	n, err := io.Copy(w.ws, r)
+	w.pos += n
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to copy data: %w", err)
+	}
+
+	return offset, n, nil
+}
+
+// WriteVideoFrame adds a new video frame to the file.
+// The raw JPEG data is copied from the given io.Reader.
+//
+// The frame is shown for the given number of frame periods.
+// This can be used to store repeated video frames only once.
+func (w *Writer) WriteVideoFrame(r io.Reader, frames int) error {
+	if frames < 1 {
+		return fmt.Errorf("invalid number of frame periods %d", frames)
+	}
+
+	offset, size, err := w.copyData(r)
+	if err != nil {
+		return err
+	}
+	if size > math.MaxUint32 {
+		return fmt.Errorf("video frame is too large: %d bytes", size)
+	}
+
+	w.video.addChunk(uint64(offset), 1)
+	w.video.sampleSizes = append(w.video.sampleSizes, uint32(size))
+	w.video.addSamples(1, uint32(frames)*w.videoSampleDuration)
+
+	return nil
+}
+
+// RepeatVideoFrame adds a video sample that references the data of an already written sample.
+// Samples are numbered in the order they were added by WriteVideoFrame and RepeatVideoFrame, starting with 0.
+//
+// The frame is shown for the given number of frame periods.
+func (w *Writer) RepeatVideoFrame(sample int, frames int) error {
+	if w.closed {
+		return fmt.Errorf("the writer is already closed")
+	}
+	if sample < 0 || sample >= len(w.video.sampleSizes) {
+		return fmt.Errorf("sample %d is outside of the valid range from %d to %d", sample, 0, len(w.video.sampleSizes)-1)
+	}
+	if frames < 1 {
+		return fmt.Errorf("invalid number of frame periods %d", frames)
+	}
+
+	// Every video sample is stored in its own chunk, therefore chunks and samples share the same index.
+	w.video.addChunk(w.video.chunkOffsets[sample], 1)
+	w.video.sampleSizes = append(w.video.sampleSizes, w.video.sampleSizes[sample])
+	w.video.addSamples(1, uint32(frames)*w.videoSampleDuration)
+
+	return nil
+}
+
+// WriteAudioFrame adds the given number of audio samples to the file.
+// The raw audio data is copied from the given io.Reader, its encoding has to match the audio format of the MXV info.
+func (w *Writer) WriteAudioFrame(r io.Reader, samples uint32) error {
+	if !w.info.HasAudio {
+		return fmt.Errorf("the file is written without audio")
+	}
+
+	offset, size, err := w.copyData(io.LimitReader(r, int64(samples)*int64(w.info.AudioBytesPerSample)))
+	if err != nil {
+		return err
+	}
+	if size != int64(samples)*int64(w.info.AudioBytesPerSample) {
+		return fmt.Errorf("audio frame contains only %d of %d bytes", size, int64(samples)*int64(w.info.AudioBytesPerSample))
+	}
+
+	w.audio.addChunk(uint64(offset), samples)
+	w.audio.addSamples(samples, 1)
+
+	return nil
+}
+
+// Close writes the movie resource and updates the size of the mdat atom.
+// This doesn't close the underlying io.WriteSeeker.
+func (w *Writer) Close() error {
+	if w.closed {
+		return fmt.Errorf("the writer is already closed")
+	}
+	w.closed = true
+
+	if err := w.writeAt(w.mdatOffset+8, binary.BigEndian.AppendUint64(nil, uint64(w.pos-w.mdatOffset))); err != nil {
+		return fmt.Errorf("failed to update mdat size: %w", err)
+	}
+
+	moov, err := w.movieResource()
+	if err != nil {
+		return fmt.Errorf("failed to encode movie resource: %w", err)
+	}
+	if err := w.write(moov); err != nil {
+		return fmt.Errorf("failed to write movie resource: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mov
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// containerAtoms contains the types of all atoms that are parsed as containers.
+var containerAtoms = map[string]bool{"moov": true, "trak": true, "mdia": true, "minf": true, "dinf": true, "stbl": true}
+
+// atom is a parsed QuickTime atom.
+type atom struct {
+	atomType string
+	data     []byte
+	children []atom
+}
+
+// parseAtoms parses all atoms in data.
+func parseAtoms(t *testing.T, data []byte) []atom {
+	var atoms []atom
+	for len(data) > 0 {
+		if len(data) < 8 {
+			t.Fatalf("Truncated atom header.")
+		}
+		size, headerSize := uint64(binary.BigEndian.Uint32(data[0:4])), uint64(8)
+		a := atom{atomType: string(data[4:8])}
+		if size == 1 {
+			size, headerSize = binary.BigEndian.Uint64(data[8:16]), 16
+		}
+		if size < headerSize || uint64(len(data)) < size {
+			t.Fatalf("Atom %q has an invalid size of %d bytes.", a.atomType, size)
+		}
+		a.data = data[headerSize:size]
+		if containerAtoms[a.atomType] {
+			a.children = parseAtoms(t, a.data)
+		}
+		atoms = append(atoms, a)
+
+		data = data[size:]
+	}
+	return atoms
+}
+
+// find returns the first child atom with the given type.
+func (a atom) find(t *testing.T, atomType string) atom {
+	for _, child := range a.children {
+		if child.atomType == atomType {
+			return child
+		}
+	}
+	t.Fatalf("Couldn't find atom %q in %q.", atomType, a.atomType)
+	return atom{}
+}
+
+// parsedSample is a sample that is resolved by the sample tables.
+type parsedSample struct {
+	offset   uint64
+	duration uint32
+	data     []byte
+}
+
+// parseTrack resolves all samples of the given trak atom.
+// For tracks with a constant sample size, every chunk is returned as a single sample.
+func parseTrack(t *testing.T, file []byte, trak atom) (sampleEntry []byte, samples []parsedSample) {
+	stbl := trak.find(t, "mdia").find(t, "minf").find(t, "stbl")
+	be := binary.BigEndian
+
+	stsd := stbl.find(t, "stsd").data
+	sampleEntry = stsd[8:][8:be.Uint32(stsd[8:])]
+
+	var durations []uint32
+	stts := stbl.find(t, "stts").data
+	for i := range be.Uint32(stts[4:]) {
+		for range be.Uint32(stts[8+8*i:]) {
+			durations = append(durations, be.Uint32(stts[12+8*i:]))
+		}
+	}
+
+	stsz := stbl.find(t, "stsz").data
+	sampleSize := be.Uint32(stsz[4:])
+
+	co64 := stbl.find(t, "co64").data
+	chunks := be.Uint32(co64[4:])
+
+	stsc := stbl.find(t, "stsc").data
+	stscEntries := be.Uint32(stsc[4:])
+	var sample uint32
+	for chunk := range chunks {
+		// Find the sample-to-chunk entry for this chunk.
+		var samplesPerChunk uint32
+		for i := range stscEntries {
+			if be.Uint32(stsc[8+12*i:])-1 <= chunk {
+				samplesPerChunk = be.Uint32(stsc[12+12*i:])
+			}
+		}
+
+		offset := be.Uint64(co64[8+8*chunk:])
+		if sampleSize != 0 {
+			size := uint64(samplesPerChunk) * uint64(sampleSize)
+			samples = append(samples, parsedSample{offset: offset, duration: samplesPerChunk, data: file[offset : offset+size]})
+			sample += samplesPerChunk
+			continue
+		}
+		for range samplesPerChunk {
+			size := uint64(be.Uint32(stsz[12+4*sample:]))
+			samples = append(samples, parsedSample{offset: offset, duration: durations[sample], data: file[offset : offset+size]})
+			offset += size
+			sample++
+		}
+	}
+
+	if sampleSize == 0 && int(sample) != len(durations) {
+		t.Errorf("Sample tables contain %d samples, but the time-to-sample table contains %d samples.", sample, len(durations))
+	}
+
+	return sampleEntry, samples
+}
+
+// remuxAndParse remuxes the given MXV reader, and returns the moov atom and the whole file.
+func remuxAndParse(t *testing.T, r *mxv.Reader) (atom, []byte) {
+	filename := filepath.Join(t.TempDir(), "remuxed.mov")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	if err := Remux(f, r); err != nil {
+		t.Fatalf("Failed to remux: %v.", err)
+	}
+
+	file, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+	atoms := parseAtoms(t, file)
+	if len(atoms) != 3 || atoms[0].atomType != "ftyp" || atoms[1].atomType != "mdat" || atoms[2].atomType != "moov" {
+		t.Fatalf("Expected ftyp, mdat and moov atoms.")
+	}
+
+	return atoms[2], file
+}
+
+func TestRemux(t *testing.T) {
+	src, err := os.Open(filepath.Join("..", "example-files", "25i.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer src.Close()
+
+	mxvReader, err := mxv.NewReader(src)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	moov, file := remuxAndParse(t, mxvReader)
+
+	var traks []atom
+	for _, a := range moov.children {
+		if a.atomType == "trak" {
+			traks = append(traks, a)
+		}
+	}
+	if len(traks) != 2 {
+		t.Fatalf("Unexpected number of tracks. Got %d, want %d.", len(traks), 2)
+	}
+
+	videoSampleEntry, videoSamples := parseTrack(t, file, traks[0])
+	if len(videoSamples) != int(mxvReader.Info.VideoFrames) {
+		t.Fatalf("Unexpected number of video samples. Got %d, want %d.", len(videoSamples), mxvReader.Info.VideoFrames)
+	}
+	for frame := range mxvReader.VideoFrames() {
+		r, err := mxvReader.VideoFrameData(frame)
+		if err != nil {
+			t.Fatalf("Failed to get video data stream: %v.", err)
+		}
+		want, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read video data stream: %v.", err)
+		}
+		if !bytes.Equal(videoSamples[frame].data, want) {
+			t.Errorf("Video frame %d differs from source. Got %d bytes, want %d bytes.", frame, len(videoSamples[frame].data), len(want))
+		}
+	}
+
+	// 1440x1080 with an aspect ratio of 16:9 has a pixel aspect ratio of 4:3.
+	extensions := parseAtoms(t, videoSampleEntry[78:])
+	if len(extensions) != 2 || extensions[1].atomType != "pasp" || !bytes.Equal(extensions[1].data, []byte{0, 0, 0, 4, 0, 0, 0, 3}) {
+		t.Errorf("Unexpected sample description extensions %+v.", extensions)
+	}
+
+	_, audioChunks := parseTrack(t, file, traks[1])
+	if got := string(traks[1].find(t, "mdia").find(t, "minf").find(t, "stbl").find(t, "stsd").data[12:16]); got != "sowt" {
+		t.Errorf("Unexpected audio sample description format. Got %q, want %q.", got, "sowt")
+	}
+	var audioData []byte
+	for _, chunk := range audioChunks {
+		audioData = append(audioData, chunk.data...)
+	}
+	if got, want := uint64(len(audioData)), mxvReader.Info.AudioSamples*uint64(mxvReader.Info.AudioBytesPerSample); got != want {
+		t.Errorf("Unexpected audio data length. Got %d bytes, want %d bytes.", got, want)
+	}
+}
+
+// TestRemuxRepeatedFrames checks that repeated video frames are stored only once, and that the interlace flags are passed through.
+func TestRemuxRepeatedFrames(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "synthetic.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	mxvWriter, err := mxv.NewWriter(f, mxv.Info{
+		ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 16, FrameHeight: 8, Framerate: 29.97, Interlaced: true, FieldOrder: mxriff64.FieldOrderBottomFirst,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MXV writer: %v.", err)
+	}
+
+	frames := [][]byte{{0xFF, 0xD8, 1, 0xFF, 0xD9}, {0xFF, 0xD8, 2, 2, 0xFF, 0xD9}}
+	for _, frame := range frames {
+		if err := mxvWriter.WriteVideoFrame(bytes.NewReader(frame)); err != nil {
+			t.Fatalf("Failed to write video frame: %v.", err)
+		}
+	}
+	for _, frame := range []int{1, 0} {
+		if err := mxvWriter.RepeatVideoFrame(frame); err != nil {
+			t.Fatalf("Failed to repeat video frame: %v.", err)
+		}
+	}
+	if err := mxvWriter.Close(); err != nil {
+		t.Fatalf("Failed to close MXV writer: %v.", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v.", err)
+	}
+	mxvReader, err := mxv.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	moov, file := remuxAndParse(t, mxvReader)
+
+	videoSampleEntry, videoSamples := parseTrack(t, file, moov.find(t, "trak"))
+
+	// The framerate 29.97 is recovered as 30000/1001, so the time scale is 30000 and every frame has a duration of 1001.
+	wantSamples := []parsedSample{{duration: 1001, data: frames[0]}, {duration: 2002, data: frames[1]}, {duration: 1001, data: frames[0]}}
+	if len(videoSamples) != len(wantSamples) {
+		t.Fatalf("Unexpected number of video samples. Got %d, want %d.", len(videoSamples), len(wantSamples))
+	}
+	for i, want := range wantSamples {
+		got := videoSamples[i]
+		if got.duration != want.duration || !bytes.Equal(got.data, want.data) {
+			t.Errorf("Video sample %d differs. Got %+v, want %+v.", i, got, want)
+		}
+	}
+	if videoSamples[0].offset != videoSamples[2].offset {
+		t.Errorf("Repeated video frame is stored twice.")
+	}
+
+	extensions := parseAtoms(t, videoSampleEntry[78:])
+	if len(extensions) != 2 || extensions[0].atomType != "fiel" || !bytes.Equal(extensions[0].data, []byte{2, fieldDetailBottomFirst}) {
+		t.Errorf("Unexpected sample description extensions %+v.", extensions)
+	}
+}
@@ -6,18 +6,30 @@
 package mxriff64
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"io"
 )
 
-// An accessor wraps any io.Reader, io.Seeker and/or io.Writer.
+// An accessor wraps any io.Reader, io.Seeker, io.Writer, io.ReaderAt and/or io.WriterAt.
 // The io interfaces are optional and can be nil.
 type Accessor struct {
 	io.Reader
 	io.Seeker
-	//io.Writer // TODO: This could be easily used to extend the MXRIFF64 lib with write support
+	io.Writer
+	io.ReaderAt // Optional, used to read chunks and their data without touching the shared file offset.
+	io.WriterAt // Optional, used to back-patch chunk headers without seeking.
 
 	Pos int64 // The current file offset.
+
+	Registry *Registry // The registry that is used to parse chunks. If nil, DefaultRegistry is used.
+
+	// If set, chunks that extend beyond the end of the file are not rejected.
+	// This can be used to read truncated files, e.g. from crashed captures.
+	AllowTruncated bool
+
+	size int64 // The size of the data source, or 0 if unknown. Chunks are checked against this size.
 }
 
 // Starting point for reading a MXRIFF64 container based on an io.Reader.
@@ -28,11 +40,57 @@ func NewFromReader(b io.Reader) *Accessor {
 }
 
 // Starting point for reading a MXRIFF64 container based on an io.ReadSeeker.
+//
+// The size of the data is determined by seeking to the end of b and back.
 func NewFromReadSeeker(b io.ReadSeeker) *Accessor {
 	return &Accessor{
 		Reader: b,
 		Seeker: b,
+		size:   seekerSize(b),
+	}
+}
+
+// Starting point for reading a MXRIFF64 container based on an io.ReaderAt with the given size.
+//
+// Chunks read with ReadChunk64At get their own accessor, and the data readers of chunks are independent io.SectionReaders.
+// This allows reading several chunks concurrently.
+func NewFromReaderAt(ra io.ReaderAt, size int64) *Accessor {
+	sr := io.NewSectionReader(ra, 0, size)
+	return &Accessor{
+		Reader:   sr,
+		Seeker:   sr,
+		ReaderAt: ra,
+		size:     size,
+	}
+}
+
+// Starting point for writing a MXRIFF64 container based on an io.WriteSeeker.
+//
+// If b also implements io.WriterAt, it will be used to back-patch chunk headers.
+func NewFromWriteSeeker(b io.WriteSeeker) *Accessor {
+	a := &Accessor{
+		Seeker: b,
+		Writer: b,
+	}
+	if wa, ok := b.(io.WriterAt); ok {
+		a.WriterAt = wa
+	}
+	return a
+}
+
+// Starting point for reading and modifying a MXRIFF64 container based on an io.ReadWriteSeeker.
+//
+// If b also implements io.WriterAt, it will be used to back-patch chunk headers.
+func NewFromReadWriteSeeker(b io.ReadWriteSeeker) *Accessor {
+	a := &Accessor{
+		Reader: b,
+		Seeker: b,
+		Writer: b,
 	}
+	if wa, ok := b.(io.WriterAt); ok {
+		a.WriterAt = wa
+	}
+	return a
 }
 
 func (a *Accessor) Read(p []byte) (n int, err error) {
@@ -91,7 +149,7 @@ func (a *Accessor) Seek(offset int64, whence int) (o int64, err error) {
 	return a.Pos, fmt.Errorf("the accessor doesn't support seeking")
 }
 
-/*func (a *Accessor) Write(p []byte) (n int, err error) {
+func (a *Accessor) Write(p []byte) (n int, err error) {
 	if a.Writer != nil {
 		n, err = a.Writer.Write(p)
 		a.Pos += int64(n)
@@ -99,4 +157,193 @@ func (a *Accessor) Seek(offset int64, whence int) (o int64, err error) {
 	}
 
 	return 0, fmt.Errorf("the accessor doesn't support writing")
-}*/
+}
+
+// WriteAt writes p at the given file offset.
+//
+// If there is no io.WriterAt, this will seek to the given offset, write the data and seek back to the current position.
+// In any case, the file offset stored in Pos is unchanged afterwards.
+func (a *Accessor) WriteAt(p []byte, off int64) (n int, err error) {
+	if a.WriterAt != nil {
+		return a.WriterAt.WriteAt(p, off)
+	}
+
+	if a.Writer == nil {
+		return 0, fmt.Errorf("the accessor doesn't support writing")
+	}
+	if a.Seeker == nil {
+		return 0, fmt.Errorf("the accessor doesn't support writing at arbitrary offsets")
+	}
+
+	pos := a.Pos
+	if _, err := a.Seek(off, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek to offset %d: %w", off, err)
+	}
+	n, err = a.Write(p)
+	if _, err := a.Seek(pos, io.SeekStart); err != nil {
+		return n, fmt.Errorf("failed to seek back to offset %d: %w", pos, err)
+	}
+
+	return n, err
+}
+
+// patchAt encodes data in little endian byte order and writes it at the given file offset.
+// The file offset stored in Pos is unchanged afterwards.
+func (a *Accessor) patchAt(off int64, data any) error {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+		return err
+	}
+
+	if _, err := a.WriteAt(buf.Bytes(), off); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// writeChunkHeader writes the given chunk identifier and header, followed by any fixed size data, into "a" at its current position.
+// It returns the file offset after the written values, which is where any variable length chunk data starts.
+func (a *Accessor) writeChunkHeader(identifier fmt.Stringer, header any, data ...any) (int64, error) {
+	if a == nil {
+		return 0, fmt.Errorf("accessor is nil")
+	}
+
+	if err := binary.Write(a, binary.LittleEndian, identifier); err != nil {
+		return 0, fmt.Errorf("failed to write identifier of %q chunk: %w", identifier, err)
+	}
+
+	if err := binary.Write(a, binary.LittleEndian, header); err != nil {
+		return 0, fmt.Errorf("failed to write header of %q chunk: %w", identifier, err)
+	}
+
+	for _, d := range data {
+		if err := binary.Write(a, binary.LittleEndian, d); err != nil {
+			return 0, fmt.Errorf("failed to write data of %q chunk: %w", identifier, err)
+		}
+	}
+
+	return a.Pos, nil
+}
+
+// finishChunk sets dataLength to the given value, and back-patches the header of the chunk at the given file offset.
+// The header in the file is only updated if the value changed, which allows writing to non seekable writers when DataLength is set beforehand.
+func finishChunk[T int32 | int64](a *Accessor, identifier fmt.Stringer, chunkOffset int64, header any, dataLength *T, length int64) error {
+	if *dataLength == T(length) {
+		return nil
+	}
+	*dataLength = T(length)
+
+	if err := a.patchAt(chunkOffset+int64(binary.Size(identifier)), header); err != nil {
+		return fmt.Errorf("failed to update header of %q chunk: %w", identifier, err)
+	}
+
+	return nil
+}
+
+// seekerSize returns the size of the data behind s, or 0 if it can't be determined.
+// The current position of s is restored afterwards.
+func seekerSize(s io.Seeker) int64 {
+	pos, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0
+	}
+	size, err := s.Seek(0, io.SeekEnd)
+	if _, err := s.Seek(pos, io.SeekStart); err != nil {
+		return 0
+	}
+	if err != nil {
+		return 0
+	}
+	return size
+}
+
+// Size returns the size of the data source, or 0 if it is unknown.
+//
+// The size is known for accessors that are created with NewFromReaderAt and NewFromReadSeeker.
+func (a *Accessor) Size() int64 {
+	return a.size
+}
+
+// registry returns the registry that is used to parse chunks.
+func (a *Accessor) registry() *Registry {
+	if a.Registry != nil {
+		return a.Registry
+	}
+	return DefaultRegistry
+}
+
+// fork returns a new accessor that reads from the io.ReaderAt of "a", starting at the given file offset.
+// The new accessor uses the same registry, but doesn't share any other state with "a".
+func (a *Accessor) fork(off int64) (*Accessor, error) {
+	b := NewFromReaderAt(a.ReaderAt, a.size)
+	b.Registry, b.AllowTruncated = a.Registry, a.AllowTruncated
+	if _, err := b.Seek(off, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ReadChunk64At parses the chunk at the given file offset, see ReadChunk64.
+//
+// If the accessor has an io.ReaderAt, the chunk is read by a new accessor that doesn't share any state with "a".
+// This can be called concurrently, and the returned chunk can be used independently of any other chunk.
+// Otherwise "a" is seeked to the given offset, and the chunk is read from there.
+func (a *Accessor) ReadChunk64At(off int64) (Chunk64, error) {
+	if a.ReaderAt != nil {
+		b, err := a.fork(off)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seek to offset %d: %w", off, err)
+		}
+		return b.ReadChunk64()
+	}
+
+	if _, err := a.Seek(off, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek to offset %d: %w", off, err)
+	}
+	return a.ReadChunk64()
+}
+
+// dataReader returns an io.Reader for n bytes at the given file offset.
+//
+// If the accessor has an io.ReaderAt, the result is an independent io.SectionReader.
+// Otherwise "a" is seeked to the given offset, and the returned reader reads from "a".
+func (a *Accessor) dataReader(off, n int64) (io.Reader, error) {
+	if a.ReaderAt != nil {
+		return io.NewSectionReader(a.ReaderAt, off, n), nil
+	}
+
+	if _, err := a.Seek(off, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return io.LimitReader(a, n), nil
+}
+
+// sectionReader returns an io.SectionReader for n bytes at the given file offset.
+//
+// If the accessor has an io.ReaderAt, the result is independent of "a".
+// Otherwise every read seeks "a" to the requested offset, and reads from there.
+func (a *Accessor) sectionReader(off, n int64) *io.SectionReader {
+	if a.ReaderAt != nil {
+		return io.NewSectionReader(a.ReaderAt, off, n)
+	}
+
+	return io.NewSectionReader(seekingReaderAt{a}, off, n)
+}
+
+// seekingReaderAt implements io.ReaderAt by seeking the accessor before every read.
+type seekingReaderAt struct {
+	a *Accessor
+}
+
+func (r seekingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	if _, err := r.a.Seek(off, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek to offset %d: %w", off, err)
+	}
+
+	n, err := io.ReadFull(r.a, p)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
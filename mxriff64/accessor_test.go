@@ -23,49 +23,21 @@ func TestNewFromReader(t *testing.T) {
 
 	riff := mxriff64.NewFromReader(f)
 
-	c, err := riff.ReadChunk64()
-	if err != nil {
-		t.Fatalf("Failed to read Chunk64: %v.", err)
-	}
-
-	var printChunkTree func(chunk any, level int)
-	printChunkTree = func(chunk any, level int) {
-		switch chunk := chunk.(type) {
+	err = mxriff64.Walk(riff, func(entry mxriff64.WalkEntry) error {
+		indent := strings.Repeat("\t", entry.Depth)
+		switch chunk := entry.Chunk.(type) {
 		case *mxriff64.Chunk64MXRIFF64:
-			log.Printf("%sChunk %s | Total length: %d bytes | %s.", strings.Repeat("\t", level), chunk.Identifier(), chunk.Length(), chunk.Header.FormType)
-			log.Printf("%s  Sub-Chunks:", strings.Repeat("\t", level))
-			for sc, err := range chunk.Chunks() {
-				if err != nil {
-					t.Fatalf("Failed to read sub-chunk: %v.", err)
-				}
-				printChunkTree(sc, level+1)
-			}
+			log.Printf("%sChunk %s | Total length: %d bytes | %s.", indent, entry.Identifier, entry.Length, chunk.Header.FormType)
 		case *mxriff64.Chunk64MXLIST64:
-			log.Printf("%sChunk %s | Total length: %d bytes | %s.", strings.Repeat("\t", level), chunk.Identifier(), chunk.Length(), chunk.Header.ContentType)
-			log.Printf("%s  Sub-Chunks:", strings.Repeat("\t", level))
-			for sc, err := range chunk.Chunks() {
-				if err != nil {
-					t.Fatalf("Failed to read sub-chunk: %v.", err)
-				}
-				printChunkTree(sc, level+1)
-			}
+			log.Printf("%sChunk %s | Total length: %d bytes | %s.", indent, entry.Identifier, entry.Length, chunk.Header.ContentType)
 		case *mxriff64.Chunk64MXLIST32:
-			log.Printf("%sChunk %s | Total length: %d bytes | %s.", strings.Repeat("\t", level), chunk.Identifier(), chunk.Length(), chunk.Header.ContentType)
-			log.Printf("%s  Sub-Chunks:", strings.Repeat("\t", level))
-			for sc, err := range chunk.Chunks() {
-				if err != nil {
-					t.Fatalf("Failed to read sub-chunk: %v.", err)
-				}
-				printChunkTree(sc, level+1)
-			}
-		case mxriff64.Chunk32:
-			log.Printf("%sChunk %s | Total length: %d bytes.", strings.Repeat("\t", level), chunk.Identifier(), chunk.Length())
-		case mxriff64.Chunk64:
-			log.Printf("%sChunk %s | Total length: %d bytes.", strings.Repeat("\t", level), chunk.Identifier(), chunk.Length())
+			log.Printf("%sChunk %s | Total length: %d bytes | %s.", indent, entry.Identifier, entry.Length, chunk.Header.ContentType)
 		default:
-			t.Fatalf("Invalid object %T passed to printChunkTree.", chunk)
+			log.Printf("%sChunk %s | Total length: %d bytes.", indent, entry.Identifier, entry.Length)
 		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk chunk tree: %v.", err)
 	}
-
-	printChunkTree(c, 0)
 }
@@ -35,13 +35,50 @@ func (c *Chunk32Dummy) Length() int32 {
 	return 4 + 4 + c.Header.DataLength
 }
 
+// Offset returns the file offset of the chunk, which is where its identifier starts.
+func (c *Chunk32Dummy) Offset() int64 {
+	return c.dataStartOffset - c.HeaderLength()
+}
+
+// HeaderLength returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+func (c *Chunk32Dummy) HeaderLength() int64 {
+	return 4 + int64(binary.Size(c.Header))
+}
+
+// RawReader returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+func (c *Chunk32Dummy) RawReader() *io.SectionReader {
+	return c.Accessor.sectionReader(c.Offset(), int64(c.Length()))
+}
+
 // Returns an io.Reader with the chunk data.
 func (c *Chunk32Dummy) DataReader() (io.Reader, error) {
-	if _, err := c.Accessor.Seek(c.dataStartOffset, io.SeekStart); err != nil {
+	r, err := c.Accessor.dataReader(c.dataStartOffset, int64(c.Header.DataLength))
+	if err != nil {
 		return nil, fmt.Errorf("failed to seek to the start of the chunk data: %w", err)
 	}
 
-	return io.LimitReader(c.Accessor, int64(c.Header.DataLength)), nil
+	return r, nil
+}
+
+// Writes the chunk identifier and header into "a" at its current position.
+//
+// The chunk data can then be written into "a".
+// Once everything is written, Close has to be called to finish the chunk.
+func (c *Chunk32Dummy) WriteChunk(a *Accessor) error {
+	c.Accessor = a
+
+	dataStartOffset, err := a.writeChunkHeader(c.Identifier(), &c.Header)
+	if err != nil {
+		return err
+	}
+	c.dataStartOffset = dataStartOffset
+
+	return nil
+}
+
+// Close finishes a chunk that was started with WriteChunk, see Chunk32Writer.
+func (c *Chunk32Dummy) Close() error {
+	return finishChunk(c.Accessor, c.Identifier(), c.Offset(), &c.Header, &c.Header.DataLength, c.Accessor.Pos-c.dataStartOffset)
 }
 
 // Parses the data from "a" and returns a Chunk32 that can be used to further inspect the chunk content.
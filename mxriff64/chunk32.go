@@ -8,14 +8,24 @@ package mxriff64
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 )
 
 type Chunk32 interface {
 	Identifier() Identifier32 // Returns the identifier of the chunk.
 	Length() int32            // Returns the total length of the chunk, including headers and such.
+	Offset() int64            // Returns the file offset of the chunk, which is where its identifier starts.
+	HeaderLength() int64      // Returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+
+	// Returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+	//
+	// If the accessor of the chunk has no io.ReaderAt, reading from the section reader seeks the accessor.
+	RawReader() *io.SectionReader
 }
 
 // ReadChunk32 parses the chunk from "a" and returns a Chunk32 that can be used to further inspect the chunk content.
+//
+// If the length of the chunk is less than its headers, or if the chunk extends beyond the end of the file, a *ChunkLengthError is returned.
 func (a *Accessor) ReadChunk32() (Chunk32, error) {
 	var id Identifier32
 
@@ -23,13 +33,24 @@ func (a *Accessor) ReadChunk32() (Chunk32, error) {
 		return nil, fmt.Errorf("failed to read Chunk32 identifier: %w", err)
 	}
 
-	if chunk, ok := chunk32Registry[id]; ok {
-		return chunk.BuildChunk(a)
+	var chunk Chunk32
+	var err error
+	if builder, ok := a.registry().Chunk32Builder(id); ok {
+		chunk, err = builder.BuildChunk(a)
+	} else {
+		// Fall back to dummy chunk, as we want to support reading containers with unknown chunk identifiers.
+		dummyChunk := &Chunk32Dummy{}
+		chunk, err = dummyChunk.BuildChunk(a, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.checkChunk32(chunk); err != nil {
+		return nil, err
 	}
 
-	// Fall back to dummy chunk, as we want to support reading containers with unknown chunk identifiers.
-	dummyChunk := &Chunk32Dummy{}
-	return dummyChunk.BuildChunk(a, id)
+	return chunk, nil
 }
 
 type Chunk32Builder interface {
@@ -45,24 +66,16 @@ type Chunk32Builder interface {
 	BuildChunk(a *Accessor) (Chunk32, error)
 }
 
-var chunk32Registry = map[Identifier32]Chunk32Builder{}
-
-// RegisterChunk32 adds the given Chunk32 to the registry.
-func RegisterChunk32(c Chunk32Builder) error {
-	id := c.Identifier()
-
-	if _, found := chunk32Registry[id]; found {
-		return fmt.Errorf("Chunk32 with %s already exists", id)
-	}
-
-	chunk32Registry[id] = c
-
-	return nil
-}
+type Chunk32Writer interface {
+	Chunk32
 
-// MustRegisterChunk32 is similar to RegisterChunk32, but it panics on any error.
-func MustRegisterChunk32(c Chunk32Builder) {
-	if err := RegisterChunk32(c); err != nil {
-		panic(err)
-	}
+	// Writes the chunk identifier and header, as well as any fixed size data, into "a" at its current position.
+	//
+	// Chunks that contain a payload of variable length additionally implement io.Closer.
+	// For these, the payload has to be written into "a" afterwards, and the chunk has to be finished by calling Close.
+	// Close will then back-patch the DataLength field of the chunk header.
+	//
+	// DataLength is set to the number of bytes written since WriteChunk, therefore the file offset of "a" needs to be at the end of the chunk data when calling Close.
+	// The header in the file is only updated if DataLength changed, which allows writing to non seekable writers when DataLength is set beforehand.
+	WriteChunk(a *Accessor) error
 }
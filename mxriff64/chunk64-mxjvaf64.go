@@ -40,14 +40,52 @@ func (c *Chunk64MXJVAF64) Length() int64 {
 	return 8 + 8 + c.Header.DataLength
 }
 
+// Offset returns the file offset of the chunk, which is where its identifier starts.
+func (c *Chunk64MXJVAF64) Offset() int64 {
+	return c.dataStartOffset - c.HeaderLength() - int64(binary.Size(c.Data))
+}
+
+// HeaderLength returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+func (c *Chunk64MXJVAF64) HeaderLength() int64 {
+	return 8 + int64(binary.Size(c.Header))
+}
+
+// RawReader returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+func (c *Chunk64MXJVAF64) RawReader() *io.SectionReader {
+	return c.Accessor.sectionReader(c.Offset(), c.Length())
+}
+
 // Returns an io.Reader with the raw audio data.
 // The encoding of the data is stored in Chunk64MXWFMT64.Data.AudioFormat, and is similar to the wFormatTag in wav files.
 func (c *Chunk64MXJVAF64) DataReader() (io.Reader, error) {
-	if _, err := c.Accessor.Seek(c.dataStartOffset, io.SeekStart); err != nil {
+	r, err := c.Accessor.dataReader(c.dataStartOffset, c.Header.DataLength-16)
+	if err != nil {
 		return nil, fmt.Errorf("failed to seek to the beginning of the audio data: %w", err)
 	}
 
-	return io.LimitReader(c.Accessor, c.Header.DataLength-16), nil
+	return r, nil
+}
+
+// Writes the chunk identifier and header into "a" at its current position.
+//
+// The raw audio data can then be written into "a".
+// Once everything is written, Close has to be called to finish the chunk.
+func (c *Chunk64MXJVAF64) WriteChunk(a *Accessor) error {
+	c.Accessor = a
+
+	dataStartOffset, err := a.writeChunkHeader(c.Identifier(), &c.Header, &c.Data)
+	if err != nil {
+		return err
+	}
+	c.dataStartOffset = dataStartOffset
+
+	return nil
+}
+
+// Close finishes a chunk that was started with WriteChunk, see Chunk64Writer.
+func (c *Chunk64MXJVAF64) Close() error {
+	// The fixed size data in front of the audio data is included in DataLength.
+	return finishChunk(c.Accessor, c.Identifier(), c.Offset(), &c.Header, &c.Header.DataLength, c.Accessor.Pos-c.dataStartOffset+int64(binary.Size(c.Data)))
 }
 
 // Parses the data from "a" and returns a Chunk64 that can be used to further inspect the chunk content.
@@ -68,6 +106,12 @@ func (*Chunk64MXJVAF64) BuildChunk(a *Accessor) (Chunk64, error) {
 		return nil, fmt.Errorf("failed to read header of %q chunk: %w", c.Identifier(), err)
 	}
 
+	// The audio data is preceded by the fixed size data, which is included in DataLength.
+	headerLength := 8 + int64(binary.Size(c.Header))
+	if err := a.checkLength(c.Identifier().name(), a.Pos-headerLength, c.Length(), headerLength+int64(binary.Size(c.Data)), -1); err != nil {
+		return nil, err
+	}
+
 	if err := binary.Read(c, binary.LittleEndian, &c.Data); err != nil {
 		return nil, fmt.Errorf("failed to read data of %q chunk: %w", c.Identifier(), err)
 	}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Guess: MAGIX JPEG Video Color or Codec information.
+// The content is the same in all known files.
+//
+// If the chunk data is shorter than Chunk64MXJVCO64Data, ReadChunk64 returns a *Chunk64Dummy instead.
+type Chunk64MXJVCO64 struct {
+	*Accessor
+
+	Header struct {
+		DataLength int64
+	}
+
+	Data Chunk64MXJVCO64Data
+
+	dataStartOffset int64 // File offset where the chunk data starts.
+}
+
+type Chunk64MXJVCO64Data struct {
+	GUID     [16]byte // Guess: Identifies the codec or color conversion that was used to create the JPEG frames. Always 7f7496b5e371874d9a8f1c69eaa3e416 in known files.
+	Unknown1 uint64   // Always 0 in known files.
+}
+
+// Returns the identifier of the chunk.
+func (c *Chunk64MXJVCO64) Identifier() Identifier64 {
+	return Identifier64{'M', 'X', 'J', 'V', 'C', 'O', '6', '4'}
+}
+
+// Returns the total length of the chunk, including headers and such.
+func (c *Chunk64MXJVCO64) Length() int64 {
+	return 8 + 8 + c.Header.DataLength
+}
+
+// Offset returns the file offset of the chunk, which is where its identifier starts.
+func (c *Chunk64MXJVCO64) Offset() int64 {
+	return c.dataStartOffset - c.HeaderLength()
+}
+
+// HeaderLength returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+func (c *Chunk64MXJVCO64) HeaderLength() int64 {
+	return 8 + int64(binary.Size(c.Header))
+}
+
+// RawReader returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+func (c *Chunk64MXJVCO64) RawReader() *io.SectionReader {
+	return c.Accessor.sectionReader(c.Offset(), c.Length())
+}
+
+// Writes the chunk identifier, header and data into "a" at its current position.
+// The DataLength field of the header is set to the size of the data.
+func (c *Chunk64MXJVCO64) WriteChunk(a *Accessor) error {
+	c.Accessor = a
+	c.Header.DataLength = int64(binary.Size(c.Data))
+
+	dataEndOffset, err := a.writeChunkHeader(c.Identifier(), &c.Header, &c.Data)
+	if err != nil {
+		return err
+	}
+	c.dataStartOffset = dataEndOffset - int64(binary.Size(c.Data))
+
+	return nil
+}
+
+// Parses the data from "a" and returns a Chunk64 that can be used to further inspect the chunk content.
+// The seek position of "a" needs to be at the length field, as the identifier is already read and parsed.
+//
+// This function doesn't need to parse anything beside the chunk header.
+// Which enables quick iteration over chunks without storing or parsing any unnecessary data.
+//
+// Internal: This will be called by ReadChunk64 and should only be used to create new instances of chunk objects.
+func (*Chunk64MXJVCO64) BuildChunk(a *Accessor) (Chunk64, error) {
+	if a == nil {
+		return nil, fmt.Errorf("accessor is nil")
+	}
+
+	c := &Chunk64MXJVCO64{Accessor: a}
+
+	if err := binary.Read(c, binary.LittleEndian, &c.Header); err != nil {
+		return nil, fmt.Errorf("failed to read header of %q chunk: %w", c.Identifier(), err)
+	}
+	c.dataStartOffset = c.Accessor.Pos
+
+	// Other variants of this chunk may have a different length.
+	// Shorter data can't be parsed, so the chunk is kept as a dummy chunk.
+	// Any data beyond the known fields is ignored, it's still available via RawReader.
+	if c.Header.DataLength < int64(binary.Size(c.Data)) {
+		return &Chunk64Dummy{Accessor: a, ID: c.Identifier(), Header: c.Header, dataStartOffset: a.Pos}, nil
+	}
+
+	if err := binary.Read(c, binary.LittleEndian, &c.Data); err != nil {
+		return nil, fmt.Errorf("failed to read data of %q chunk: %w", c.Identifier(), err)
+	}
+
+	return c, nil
+}
+
+func init() {
+	MustRegisterChunk64(&Chunk64MXJVCO64{})
+}
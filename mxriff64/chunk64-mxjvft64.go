@@ -32,13 +32,79 @@ func (c *Chunk64MXJVFT64) Length() int64 {
 	return 8 + 8 + c.Header.DataLength
 }
 
+// Offset returns the file offset of the chunk, which is where its identifier starts.
+func (c *Chunk64MXJVFT64) Offset() int64 {
+	return c.dataStartOffset - c.HeaderLength()
+}
+
+// HeaderLength returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+func (c *Chunk64MXJVFT64) HeaderLength() int64 {
+	return 8 + int64(binary.Size(c.Header))
+}
+
+// RawReader returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+func (c *Chunk64MXJVFT64) RawReader() *io.SectionReader {
+	return c.Accessor.sectionReader(c.Offset(), c.Length())
+}
+
 // Returns an io.Reader with the chunk data.
 func (c *Chunk64MXJVFT64) DataReader() (io.Reader, error) {
-	if _, err := c.Accessor.Seek(c.dataStartOffset, io.SeekStart); err != nil {
+	r, err := c.Accessor.dataReader(c.dataStartOffset, c.Header.DataLength)
+	if err != nil {
 		return nil, fmt.Errorf("failed to seek to the start of the chunk data: %w", err)
 	}
 
-	return io.LimitReader(c.Accessor, c.Header.DataLength), nil
+	return r, nil
+}
+
+// Offsets reads and returns all entries of the offset table.
+//
+// Every entry is the file offset of the MXJVVF64 chunk of a video frame, in the same order as the VFTE entries.
+// The table ends with an extra terminating entry, which is returned separately.
+// In all known files the terminating entry is the file offset of this MXJVFT64 chunk, which is also the end of the MXJVFL64 list.
+func (c *Chunk64MXJVFT64) Offsets() (frameOffsets []int64, terminator int64, err error) {
+	if c.Header.DataLength < 8 || c.Header.DataLength%8 != 0 {
+		return nil, 0, fmt.Errorf("invalid data length of %q chunk: %d bytes", c.Identifier(), c.Header.DataLength)
+	}
+
+	r, err := c.DataReader()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Read the entries in batches, as the length field may be damaged and the size of the file isn't always known.
+	var entries []int64
+	batch := make([]int64, min(c.Header.DataLength/8, 1024))
+	for remaining := c.Header.DataLength / 8; remaining > 0; remaining -= int64(len(batch)) {
+		batch = batch[:min(remaining, int64(len(batch)))]
+		if err := binary.Read(r, binary.LittleEndian, batch); err != nil {
+			return nil, 0, fmt.Errorf("failed to read entries of %q chunk: %w", c.Identifier(), err)
+		}
+		entries = append(entries, batch...)
+	}
+
+	return entries[:len(entries)-1], entries[len(entries)-1], nil
+}
+
+// Writes the chunk identifier and header into "a" at its current position.
+//
+// The table data can then be written into "a".
+// Once everything is written, Close has to be called to finish the chunk.
+func (c *Chunk64MXJVFT64) WriteChunk(a *Accessor) error {
+	c.Accessor = a
+
+	dataStartOffset, err := a.writeChunkHeader(c.Identifier(), &c.Header)
+	if err != nil {
+		return err
+	}
+	c.dataStartOffset = dataStartOffset
+
+	return nil
+}
+
+// Close finishes a chunk that was started with WriteChunk, see Chunk64Writer.
+func (c *Chunk64MXJVFT64) Close() error {
+	return finishChunk(c.Accessor, c.Identifier(), c.Offset(), &c.Header, &c.Header.DataLength, c.Accessor.Pos-c.dataStartOffset)
 }
 
 // Parses the data from "a" and returns a Chunk64 that can be used to further inspect the chunk content.
@@ -0,0 +1,64 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// TestFrameTableOffsets reads the entries of MXJVFT64 chunks from a plain io.Reader, where the size of the file is unknown.
+func TestFrameTableOffsets(t *testing.T) {
+	entries := []int64{100, 200, 300}
+
+	tests := []struct {
+		name           string
+		dataLength     int64
+		wantOffsets    []int64
+		wantTerminator int64
+		wantErr        error // Nil if no error is expected.
+	}{
+		{"Valid", 24, entries[:2], entries[2], nil},
+		{"Huge", 1 << 40, nil, 0, io.ErrUnexpectedEOF},
+		{"Exabytes", 1 << 60, nil, 0, io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := binary.LittleEndian.AppendUint64([]byte("MXJVFT64"), uint64(tt.dataLength))
+			data, _ = binary.Append(data, binary.LittleEndian, entries)
+
+			chunk, err := mxriff64.NewFromReader(bytes.NewBuffer(data)).ReadChunk64()
+			if err != nil {
+				t.Fatalf("Failed to read chunk: %v.", err)
+			}
+			frameTable, ok := chunk.(*mxriff64.Chunk64MXJVFT64)
+			if !ok {
+				t.Fatalf("Unexpected chunk type %T.", chunk)
+			}
+
+			offsets, terminator, err := frameTable.Offsets()
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Unexpected error %v, want %v.", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read offsets: %v.", err)
+			}
+			if !slices.Equal(offsets, tt.wantOffsets) || terminator != tt.wantTerminator {
+				t.Errorf("Unexpected offsets. Got %v and %d, want %v and %d.", offsets, terminator, tt.wantOffsets, tt.wantTerminator)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ package mxriff64
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 )
 
 // MAGIX JPEG Video Header version 2.
@@ -19,6 +20,8 @@ type Chunk64MXJVH264 struct {
 	}
 
 	Data Chunk64MXJVH264Data
+
+	dataStartOffset int64 // File offset where the chunk data starts.
 }
 
 type Chunk64MXJVH264Data struct {
@@ -42,6 +45,36 @@ func (c *Chunk64MXJVH264) Length() int64 {
 	return 8 + 8 + c.Header.DataLength
 }
 
+// Offset returns the file offset of the chunk, which is where its identifier starts.
+func (c *Chunk64MXJVH264) Offset() int64 {
+	return c.dataStartOffset - c.HeaderLength()
+}
+
+// HeaderLength returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+func (c *Chunk64MXJVH264) HeaderLength() int64 {
+	return 8 + int64(binary.Size(c.Header))
+}
+
+// RawReader returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+func (c *Chunk64MXJVH264) RawReader() *io.SectionReader {
+	return c.Accessor.sectionReader(c.Offset(), c.Length())
+}
+
+// Writes the chunk identifier, header and data into "a" at its current position.
+// The DataLength field of the header is set to the size of the data.
+func (c *Chunk64MXJVH264) WriteChunk(a *Accessor) error {
+	c.Accessor = a
+	c.Header.DataLength = int64(binary.Size(c.Data))
+
+	dataEndOffset, err := a.writeChunkHeader(c.Identifier(), &c.Header, &c.Data)
+	if err != nil {
+		return err
+	}
+	c.dataStartOffset = dataEndOffset - int64(binary.Size(c.Data))
+
+	return nil
+}
+
 // Parses the data from "a" and returns a Chunk64 that can be used to further inspect the chunk content.
 // The seek position of "a" needs to be at the length field, as the identifier is already read and parsed.
 //
@@ -60,15 +93,16 @@ func (*Chunk64MXJVH264) BuildChunk(a *Accessor) (Chunk64, error) {
 		return nil, fmt.Errorf("failed to read header of %q chunk: %w", c.Identifier(), err)
 	}
 
-	dataStartOffset := c.Accessor.Pos
+	c.dataStartOffset = c.Accessor.Pos
 
 	if err := binary.Read(c, binary.LittleEndian, &c.Data); err != nil {
 		return nil, fmt.Errorf("failed to read data of %q chunk: %w", c.Identifier(), err)
 	}
 
-	readBytes := c.Accessor.Pos - dataStartOffset
+	readBytes := c.Accessor.Pos - c.dataStartOffset
 	if readBytes != c.Header.DataLength {
-		return nil, fmt.Errorf("unexpected data length in header of %T. Got %d bytes, but expect %d bytes", c, c.Header.DataLength, readBytes)
+		wantLength := c.HeaderLength() + readBytes
+		return nil, &ChunkLengthError{Err: ErrInvalidLength, Identifier: c.Identifier().name(), Offset: c.Offset(), Length: c.Length(), MinLength: wantLength, MaxLength: wantLength}
 	}
 
 	return c, nil
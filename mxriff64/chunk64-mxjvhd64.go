@@ -8,6 +8,7 @@ package mxriff64
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 )
 
 // MAGIX JPEG Video Header.
@@ -19,6 +20,8 @@ type Chunk64MXJVHD64 struct {
 	}
 
 	Data Chunk64MXJVHD64Data
+
+	dataStartOffset int64 // File offset where the chunk data starts.
 }
 
 type Chunk64MXJVHD64Data struct {
@@ -34,8 +37,79 @@ type Chunk64MXJVHD64Data struct {
 	FrameHeight      uint32
 	FrameWidth2      uint32 // Maybe needed when the video is anamorphic? It's the same as the above width in all my test files.
 	FrameHeight2     uint32 // Maybe needed when the video is anamorphic? It's the same as the above height in all my test files.
-	Flags            uint32
-	MaxJPEGSize      uint32 // The JPEG data size of the largest MXJVVF64 chunk. (The size only includes the JPEG data)
+	Flags            VideoFlags
+	MaxJPEGSize      uint32 // The size of the largest MXJVVF64 chunk, the same as its Length(). (The size includes the chunk identifier and length field and all its data)
+}
+
+// VideoFlags contains the bits of the Flags field in Chunk64MXJVHD64Data.
+//
+// Bits without a known meaning are kept as they are, see Unknown.
+type VideoFlags uint32
+
+// Bits of the Flags field in Chunk64MXJVHD64Data.
+const (
+	VideoFlagHasAudio         VideoFlags = 0b00000100 // The container has audio data.
+	VideoFlagInterlaced       VideoFlags = 0b00001000 // Guess: The frames contain two interleaved fields. Not set in any known file, even interlaced ones.
+	VideoFlagBottomFieldFirst VideoFlags = 0b00010000 // Guess: The bottom field is displayed first. Only valid together with VideoFlagInterlaced.
+
+	videoFlagsKnown = VideoFlagHasAudio | VideoFlagInterlaced | VideoFlagBottomFieldFirst
+)
+
+// FieldOrder defines which field of an interlaced frame is displayed first.
+type FieldOrder uint8
+
+const (
+	FieldOrderTopFirst    FieldOrder = iota // The top field, which contains the first line of the frame, is displayed first.
+	FieldOrderBottomFirst                   // The bottom field is displayed first.
+)
+
+func (f FieldOrder) String() string {
+	switch f {
+	case FieldOrderTopFirst:
+		return "FieldOrder:TopFirst"
+	case FieldOrderBottomFirst:
+		return "FieldOrder:BottomFirst"
+	}
+	return fmt.Sprintf("FieldOrder:%d", uint8(f))
+}
+
+// HasAudio returns whether the container has audio data.
+func (f VideoFlags) HasAudio() bool {
+	return f&VideoFlagHasAudio != 0
+}
+
+// Interlaced returns whether the frames contain two interleaved fields.
+func (f VideoFlags) Interlaced() bool {
+	return f&VideoFlagInterlaced != 0
+}
+
+// FieldOrder returns which field is displayed first.
+// This is only meaningful if Interlaced returns true.
+func (f VideoFlags) FieldOrder() FieldOrder {
+	if f&VideoFlagBottomFieldFirst != 0 {
+		return FieldOrderBottomFirst
+	}
+	return FieldOrderTopFirst
+}
+
+// Unknown returns all set bits without a known meaning.
+func (f VideoFlags) Unknown() VideoFlags {
+	return f &^ videoFlagsKnown
+}
+
+// With returns the flags with the given bits set or cleared.
+// All other bits, including unknown ones, are kept as they are.
+func (f VideoFlags) With(bits VideoFlags, set bool) VideoFlags {
+	if set {
+		return f | bits
+	}
+	return f &^ bits
+}
+
+// WithFieldOrder returns the flags with the interlacing bits set to the given values.
+// All other bits, including unknown ones, are kept as they are.
+func (f VideoFlags) WithFieldOrder(interlaced bool, order FieldOrder) VideoFlags {
+	return f.With(VideoFlagInterlaced, interlaced).With(VideoFlagBottomFieldFirst, interlaced && order == FieldOrderBottomFirst)
 }
 
 // Returns the identifier of the chunk.
@@ -48,6 +122,36 @@ func (c *Chunk64MXJVHD64) Length() int64 {
 	return 8 + 8 + c.Header.DataLength
 }
 
+// Offset returns the file offset of the chunk, which is where its identifier starts.
+func (c *Chunk64MXJVHD64) Offset() int64 {
+	return c.dataStartOffset - c.HeaderLength()
+}
+
+// HeaderLength returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+func (c *Chunk64MXJVHD64) HeaderLength() int64 {
+	return 8 + int64(binary.Size(c.Header))
+}
+
+// RawReader returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+func (c *Chunk64MXJVHD64) RawReader() *io.SectionReader {
+	return c.Accessor.sectionReader(c.Offset(), c.Length())
+}
+
+// Writes the chunk identifier, header and data into "a" at its current position.
+// The DataLength field of the header is set to the size of the data.
+func (c *Chunk64MXJVHD64) WriteChunk(a *Accessor) error {
+	c.Accessor = a
+	c.Header.DataLength = int64(binary.Size(c.Data))
+
+	dataEndOffset, err := a.writeChunkHeader(c.Identifier(), &c.Header, &c.Data)
+	if err != nil {
+		return err
+	}
+	c.dataStartOffset = dataEndOffset - int64(binary.Size(c.Data))
+
+	return nil
+}
+
 // Parses the data from "a" and returns a Chunk64 that can be used to further inspect the chunk content.
 // The seek position of "a" needs to be at the length field, as the identifier is already read and parsed.
 //
@@ -66,15 +170,16 @@ func (*Chunk64MXJVHD64) BuildChunk(a *Accessor) (Chunk64, error) {
 		return nil, fmt.Errorf("failed to read header of %q chunk: %w", c.Identifier(), err)
 	}
 
-	dataStartOffset := c.Accessor.Pos
+	c.dataStartOffset = c.Accessor.Pos
 
 	if err := binary.Read(c, binary.LittleEndian, &c.Data); err != nil {
 		return nil, fmt.Errorf("failed to read data of %q chunk: %w", c.Identifier(), err)
 	}
 
-	readBytes := c.Accessor.Pos - dataStartOffset
+	readBytes := c.Accessor.Pos - c.dataStartOffset
 	if readBytes != c.Header.DataLength {
-		return nil, fmt.Errorf("unexpected data length in header of %T. Got %d bytes, but expect %d bytes", c, c.Header.DataLength, readBytes)
+		wantLength := c.HeaderLength() + readBytes
+		return nil, &ChunkLengthError{Err: ErrInvalidLength, Identifier: c.Identifier().name(), Offset: c.Offset(), Length: c.Length(), MinLength: wantLength, MaxLength: wantLength}
 	}
 
 	return c, nil
@@ -0,0 +1,37 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64_test
+
+import (
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+func TestVideoFlags(t *testing.T) {
+	const unknown = mxriff64.VideoFlags(1<<16 | 0b11)
+	flags := unknown | mxriff64.VideoFlagHasAudio
+
+	if !flags.HasAudio() || flags.Interlaced() || flags.FieldOrder() != mxriff64.FieldOrderTopFirst {
+		t.Errorf("Unexpected decoded flags of %#b.", flags)
+	}
+	if got, want := flags.Unknown(), unknown; got != want {
+		t.Errorf("Unexpected unknown bits. Got %#b, want %#b.", got, want)
+	}
+
+	flags = flags.WithFieldOrder(true, mxriff64.FieldOrderBottomFirst)
+	if !flags.Interlaced() || flags.FieldOrder() != mxriff64.FieldOrderBottomFirst {
+		t.Errorf("Unexpected decoded flags of %#b.", flags)
+	}
+	if got, want := flags, unknown|mxriff64.VideoFlagHasAudio|mxriff64.VideoFlagInterlaced|mxriff64.VideoFlagBottomFieldFirst; got != want {
+		t.Errorf("Unknown bits were not kept. Got %#b, want %#b.", got, want)
+	}
+
+	flags = flags.WithFieldOrder(false, mxriff64.FieldOrderBottomFirst).With(mxriff64.VideoFlagHasAudio, false)
+	if got, want := flags, unknown; got != want {
+		t.Errorf("Unexpected flags after clearing. Got %#b, want %#b.", got, want)
+	}
+}
@@ -32,13 +32,50 @@ func (c *Chunk64MXJVVF64) Length() int64 {
 	return 8 + 8 + c.Header.DataLength
 }
 
+// Offset returns the file offset of the chunk, which is where its identifier starts.
+func (c *Chunk64MXJVVF64) Offset() int64 {
+	return c.dataStartOffset - c.HeaderLength()
+}
+
+// HeaderLength returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+func (c *Chunk64MXJVVF64) HeaderLength() int64 {
+	return 8 + int64(binary.Size(c.Header))
+}
+
+// RawReader returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+func (c *Chunk64MXJVVF64) RawReader() *io.SectionReader {
+	return c.Accessor.sectionReader(c.Offset(), c.Length())
+}
+
 // Returns an io.Reader with the raw JPEG data.
 func (c *Chunk64MXJVVF64) DataReader() (io.Reader, error) {
-	if _, err := c.Accessor.Seek(c.dataStartOffset, io.SeekStart); err != nil {
+	r, err := c.Accessor.dataReader(c.dataStartOffset, c.Header.DataLength)
+	if err != nil {
 		return nil, fmt.Errorf("failed to seek to the start of raw JPEG data: %w", err)
 	}
 
-	return io.LimitReader(c.Accessor, c.Header.DataLength), nil
+	return r, nil
+}
+
+// Writes the chunk identifier and header into "a" at its current position.
+//
+// The raw JPEG data can then be written into "a".
+// Once everything is written, Close has to be called to finish the chunk.
+func (c *Chunk64MXJVVF64) WriteChunk(a *Accessor) error {
+	c.Accessor = a
+
+	dataStartOffset, err := a.writeChunkHeader(c.Identifier(), &c.Header)
+	if err != nil {
+		return err
+	}
+	c.dataStartOffset = dataStartOffset
+
+	return nil
+}
+
+// Close finishes a chunk that was started with WriteChunk, see Chunk64Writer.
+func (c *Chunk64MXJVVF64) Close() error {
+	return finishChunk(c.Accessor, c.Identifier(), c.Offset(), &c.Header, &c.Header.DataLength, c.Accessor.Pos-c.dataStartOffset)
 }
 
 // Parses the data from "a" and returns a Chunk64 that can be used to further inspect the chunk content.
@@ -33,10 +33,26 @@ func (c *Chunk64MXLIST32) Length() int64 {
 	return 8 + 8 + 8 + c.Header.DataLength
 }
 
+// Offset returns the file offset of the chunk, which is where its identifier starts.
+func (c *Chunk64MXLIST32) Offset() int64 {
+	return c.dataStartOffset - c.HeaderLength()
+}
+
+// HeaderLength returns the length of the chunk identifier and header, which is where the chunk data starts relative to Offset.
+func (c *Chunk64MXLIST32) HeaderLength() int64 {
+	return 8 + int64(binary.Size(c.Header))
+}
+
+// RawReader returns an io.SectionReader with the raw bytes of the whole chunk, including its identifier and header.
+func (c *Chunk64MXLIST32) RawReader() *io.SectionReader {
+	return c.Accessor.sectionReader(c.Offset(), c.Length())
+}
+
 // Chunks returns an iterator listing all sub-chunks.
 //
 // Any error is returned as the second value.
 // In case there is an error, the iteration will stop.
+// Sub-chunks that extend beyond this chunk result in a *ChunkLengthError that wraps ErrChunkOverrun.
 func (c *Chunk64MXLIST32) Chunks() iter.Seq2[Chunk32, error] {
 	return func(yield func(Chunk32, error) bool) {
 		chunkPos := c.dataStartOffset // The file offset where the current chunk starts.
@@ -52,8 +68,8 @@ func (c *Chunk64MXLIST32) Chunks() iter.Seq2[Chunk32, error] {
 				return
 			}
 
-			if chunkPos+int64(sc.Length()) > c.dataStartOffset+int64(c.Header.DataLength) {
-				yield(nil, fmt.Errorf("the sub-chunk goes beyond the parent chunk"))
+			if err := c.Accessor.checkLength(sc.Identifier().name(), chunkPos, int64(sc.Length()), sc.HeaderLength(), c.dataStartOffset+c.Header.DataLength); err != nil {
+				yield(nil, err)
 				return
 			}
 
@@ -66,6 +82,44 @@ func (c *Chunk64MXLIST32) Chunks() iter.Seq2[Chunk32, error] {
 	}
 }
 
+// SubChunks returns an iterator listing all sub-chunks together with their file offsets, see ContainerChunk.
+func (c *Chunk64MXLIST32) SubChunks() iter.Seq2[SubChunk, error] {
+	return func(yield func(SubChunk, error) bool) {
+		chunkPos := c.dataStartOffset // The file offset where the current chunk starts.
+		for sc, err := range c.Chunks() {
+			if err != nil {
+				yield(SubChunk{}, err)
+				return
+			}
+			if !yield(SubChunk{Chunk: sc, Offset: chunkPos}, nil) {
+				return
+			}
+			chunkPos += int64(sc.Length())
+		}
+	}
+}
+
+// Writes the chunk identifier and header into "a" at its current position.
+//
+// The sub-chunks can then be written into "a".
+// Once everything is written, Close has to be called to finish the chunk.
+func (c *Chunk64MXLIST32) WriteChunk(a *Accessor) error {
+	c.Accessor = a
+
+	dataStartOffset, err := a.writeChunkHeader(c.Identifier(), &c.Header)
+	if err != nil {
+		return err
+	}
+	c.dataStartOffset = dataStartOffset
+
+	return nil
+}
+
+// Close finishes a chunk that was started with WriteChunk, see Chunk64Writer.
+func (c *Chunk64MXLIST32) Close() error {
+	return finishChunk(c.Accessor, c.Identifier(), c.Offset(), &c.Header, &c.Header.DataLength, c.Accessor.Pos-c.dataStartOffset)
+}
+
 // Parses the data from "a" and returns a Chunk64 that can be used to further inspect the chunk content.
 // The seek position of "a" needs to be at the length field, as the identifier is already read and parsed.
 //
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// TestChunkRaw checks the file offset, header length and raw bytes of every chunk in an example file.
+func TestChunkRaw(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	// The common methods of Chunk64 and Chunk32.
+	type rawChunk interface {
+		Offset() int64
+		HeaderLength() int64
+		RawReader() *io.SectionReader
+	}
+
+	accessors := map[string]func() *mxriff64.Accessor{
+		"ReadSeeker": func() *mxriff64.Accessor { return mxriff64.NewFromReadSeeker(bytes.NewReader(data)) },
+		"ReaderAt":   func() *mxriff64.Accessor { return mxriff64.NewFromReaderAt(bytes.NewReader(data), int64(len(data))) },
+	}
+
+	for name, newAccessor := range accessors {
+		t.Run(name, func(t *testing.T) {
+			var chunks int
+			err := mxriff64.Walk(newAccessor(), func(entry mxriff64.WalkEntry) error {
+				chunks++
+				chunk := entry.Chunk.(rawChunk)
+
+				if chunk.Offset() != entry.Offset {
+					t.Errorf("Chunk %v has offset %d, want %d.", entry.Path, chunk.Offset(), entry.Offset)
+				}
+
+				headerLength := chunk.HeaderLength()
+				if headerLength < 8 || headerLength > entry.Length {
+					t.Errorf("Chunk %v has an invalid header length %d.", entry.Path, headerLength)
+				}
+
+				raw, err := io.ReadAll(chunk.RawReader())
+				if err != nil {
+					t.Fatalf("Failed to read raw chunk %v: %v.", entry.Path, err)
+				}
+				if want := data[entry.Offset : entry.Offset+entry.Length]; !bytes.Equal(raw, want) {
+					t.Errorf("Raw bytes of chunk %v differ from the file. Got %d bytes, want %d bytes.", entry.Path, len(raw), len(want))
+				}
+
+				// The DataLength field is always the first header field, and it covers everything after the header.
+				switch len(entry.Identifier) {
+				case 8:
+					if dataLength := int64(binary.LittleEndian.Uint64(raw[8:])); headerLength+dataLength != entry.Length {
+						t.Errorf("Chunk %v has a header length of %d, want %d.", entry.Path, headerLength, entry.Length-dataLength)
+					}
+				case 4:
+					if dataLength := int64(binary.LittleEndian.Uint32(raw[4:])); headerLength+dataLength != entry.Length {
+						t.Errorf("Chunk %v has a header length of %d, want %d.", entry.Path, headerLength, entry.Length-dataLength)
+					}
+				}
+
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to walk chunk tree: %v.", err)
+			}
+			if chunks == 0 {
+				t.Errorf("No chunks visited.")
+			}
+		})
+	}
+}
+
+// TestChunkLength checks that chunks with corrupt length fields are rejected.
+func TestChunkLength(t *testing.T) {
+	// Returns the raw bytes of a chunk with the given identifier, header values and data.
+	chunk := func(id string, header []any, data []byte) []byte {
+		var buf bytes.Buffer
+		buf.WriteString(id)
+		for _, v := range header {
+			binary.Write(&buf, binary.LittleEndian, v)
+		}
+		buf.Write(data)
+		return buf.Bytes()
+	}
+
+	file, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+	truncated := file[:len(file)/2]
+
+	dummy := chunk("XXXXXXXX", []any{int64(32)}, make([]byte, 32))
+	list := chunk("MXLIST64", []any{int64(24), [8]byte{'T', 'E', 'S', 'T', 'L', 'S', '6', '4'}}, dummy) // The sub-chunk list is 24 bytes long, but the sub-chunk has 48 bytes.
+
+	tests := []struct {
+		name string
+		data []byte
+		want *mxriff64.ChunkLengthError // Nil if no error is expected.
+	}{
+		{"Valid", dummy, nil},
+		{"Negative", chunk("XXXXXXXX", []any{int64(-5)}, nil), &mxriff64.ChunkLengthError{Err: mxriff64.ErrInvalidLength, Identifier: "XXXXXXXX", Length: 11, MinLength: 16, MaxLength: 16}},
+		{"Overflow", chunk("XXXXXXXX", []any{int64(math.MaxInt64)}, nil), &mxriff64.ChunkLengthError{Err: mxriff64.ErrInvalidLength, Identifier: "XXXXXXXX", Length: math.MinInt64 + 15, MinLength: 16, MaxLength: 16}},
+		{"BeyondFile", chunk("XXXXXXXX", []any{int64(100)}, make([]byte, 10)), &mxriff64.ChunkLengthError{Err: mxriff64.ErrTruncated, Identifier: "XXXXXXXX", Length: 116, MinLength: 16, MaxLength: 26}},
+		{"Chunk32Negative", chunk("MXLIST32", []any{int64(8), [8]byte{}}, chunk("XXXX", []any{int32(-9)}, nil)), &mxriff64.ChunkLengthError{Err: mxriff64.ErrInvalidLength, Identifier: "XXXX", Offset: 24, Length: -1, MinLength: 8, MaxLength: 8}},
+		{"BeyondParent", list, &mxriff64.ChunkLengthError{Err: mxriff64.ErrChunkOverrun, Identifier: "XXXXXXXX", Offset: 24, Length: 48, MinLength: 16, MaxLength: 24}},
+		{"FixedSize", chunk("MXWFMT64", []any{int64(20)}, make([]byte, 20)), &mxriff64.ChunkLengthError{Err: mxriff64.ErrInvalidLength, Identifier: "MXWFMT64", Length: 36, MinLength: 34, MaxLength: 34}},
+		{"AudioFrame", chunk("MXJVAF64", []any{int64(8)}, make([]byte, 16)), &mxriff64.ChunkLengthError{Err: mxriff64.ErrInvalidLength, Identifier: "MXJVAF64", Length: 24, MinLength: 32, MaxLength: 32}},
+		{"Truncated", truncated, &mxriff64.ChunkLengthError{Err: mxriff64.ErrTruncated, Identifier: "MXRIFF64", Length: int64(len(file)), MinLength: 24, MaxLength: int64(len(truncated))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := mxriff64.Walk(mxriff64.NewFromReadSeeker(bytes.NewReader(tt.data)), func(entry mxriff64.WalkEntry) error { return nil })
+
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Unexpected error: %v.", err)
+				}
+				return
+			}
+
+			var got *mxriff64.ChunkLengthError
+			if !errors.As(err, &got) {
+				t.Fatalf("Expected a *ChunkLengthError, got %v.", err)
+			}
+			if *got != *tt.want {
+				t.Errorf("Unexpected error. Got %+v, want %+v.", *got, *tt.want)
+			}
+			if !errors.Is(err, tt.want.Err) {
+				t.Errorf("Error %v doesn't wrap %v.", err, tt.want.Err)
+			}
+		})
+	}
+
+	// Truncated chunks can be read if explicitly allowed.
+	a := mxriff64.NewFromReadSeeker(bytes.NewReader(truncated))
+	a.AllowTruncated = true
+	if _, err := a.ReadChunk64(); err != nil {
+		t.Errorf("Failed to read truncated chunk: %v.", err)
+	}
+}
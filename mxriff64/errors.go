@@ -0,0 +1,75 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors that describe the kind of a problem.
+// They are wrapped by the typed errors of this package, use errors.Is to check for them.
+var (
+	ErrInvalidLength = errors.New("the length field of the chunk is invalid")   // The chunk is shorter than its headers, or its length doesn't match its fixed size data.
+	ErrChunkOverrun  = errors.New("the sub-chunk goes beyond the parent chunk") // The chunk extends beyond the end of its parent chunk.
+	ErrTruncated     = errors.New("the chunk goes beyond the end of the file")  // The chunk extends beyond the end of the file, which is most likely truncated.
+)
+
+// ChunkLengthError is returned when the length field of a chunk contains an invalid value.
+//
+// This is the case if the chunk is shorter than its headers and fixed size data, or if it extends beyond its parent chunk or the end of the file.
+type ChunkLengthError struct {
+	Err        error  // The kind of the problem: ErrInvalidLength, ErrChunkOverrun or ErrTruncated.
+	Identifier string // The identifier of the chunk, e.g. "MXLIST64".
+	Offset     int64  // File offset of the chunk identifier.
+	Length     int64  // Total length of the chunk according to its header.
+	MinLength  int64  // The minimum valid length.
+	MaxLength  int64  // The maximum valid length, which is limited by the parent chunk or the end of the file. This is -1 if there is no known limit.
+}
+
+func (e *ChunkLengthError) Error() string {
+	if e.MaxLength < 0 {
+		return fmt.Sprintf("%v: %q chunk at offset %d has a length of %d bytes, want at least %d bytes", e.Err, e.Identifier, e.Offset, e.Length, e.MinLength)
+	}
+	return fmt.Sprintf("%v: %q chunk at offset %d has a length of %d bytes, want between %d and %d bytes", e.Err, e.Identifier, e.Offset, e.Length, e.MinLength, e.MaxLength)
+}
+
+func (e *ChunkLengthError) Unwrap() error {
+	return e.Err
+}
+
+// checkLength returns a *ChunkLengthError if the given length is less than minLength, or if the chunk extends beyond end or the end of the file.
+// The end parameter is the end of the parent chunk, and is ignored if it is negative.
+func (a *Accessor) checkLength(id string, offset, length, minLength, end int64) error {
+	err := ErrChunkOverrun
+	if a.size > 0 && !a.AllowTruncated && (end < 0 || a.size < end) {
+		end, err = a.size, ErrTruncated
+	}
+
+	maxLength := int64(-1)
+	if end >= 0 {
+		maxLength = end - offset
+	}
+
+	switch {
+	case length < minLength:
+		return &ChunkLengthError{Err: ErrInvalidLength, Identifier: id, Offset: offset, Length: length, MinLength: minLength, MaxLength: maxLength}
+	case maxLength >= 0 && length > maxLength:
+		return &ChunkLengthError{Err: err, Identifier: id, Offset: offset, Length: length, MinLength: minLength, MaxLength: maxLength}
+	}
+
+	return nil
+}
+
+// checkChunk64 checks the length of the given chunk against its headers and the end of the file, see checkLength.
+func (a *Accessor) checkChunk64(c Chunk64) error {
+	return a.checkLength(c.Identifier().name(), c.Offset(), c.Length(), c.HeaderLength(), -1)
+}
+
+// checkChunk32 checks the length of the given chunk against its headers and the end of the file, see checkLength.
+func (a *Accessor) checkChunk32(c Chunk32) error {
+	return a.checkLength(c.Identifier().name(), c.Offset(), int64(c.Length()), c.HeaderLength(), -1)
+}
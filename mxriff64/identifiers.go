@@ -21,12 +21,22 @@ func (i Identifier32) String() string {
 	return "Identifier32:" + string(i[:])
 }
 
+// name returns the identifier as plain string, e.g. "VFTE".
+func (i Identifier32) name() string {
+	return string(i[:])
+}
+
 type Identifier64 [8]byte
 
 func (i Identifier64) String() string {
 	return "Identifier64:" + string(i[:])
 }
 
+// name returns the identifier as plain string, e.g. "MXLIST64".
+func (i Identifier64) name() string {
+	return string(i[:])
+}
+
 type ContentType [8]byte
 
 func (i ContentType) String() string {
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Registry maps chunk identifiers to the builders that parse the respective chunks.
+//
+// Registries can be stacked: A registry created with a parent falls back to the parent for all identifiers it doesn't contain itself.
+// This allows overriding single builders, e.g. in tests or with an experimental parser, without touching DefaultRegistry.
+//
+// A registry can be used concurrently.
+type Registry struct {
+	parent *Registry
+
+	mutex   sync.RWMutex
+	chunk64 map[Identifier64]Chunk64Builder
+	chunk32 map[Identifier32]Chunk32Builder
+}
+
+// DefaultRegistry contains the builders of all chunk types of this package.
+// It is used by all accessors that don't have their own registry.
+var DefaultRegistry = NewRegistry(nil)
+
+// NewRegistry returns a new empty registry.
+//
+// If parent is not nil, the new registry falls back to it for every identifier it doesn't contain.
+// Use DefaultRegistry as parent to override or extend the builders of this package.
+func NewRegistry(parent *Registry) *Registry {
+	return &Registry{
+		parent:  parent,
+		chunk64: map[Identifier64]Chunk64Builder{},
+		chunk32: map[Identifier32]Chunk32Builder{},
+	}
+}
+
+// RegisterChunk64 adds the given Chunk64 to the registry.
+//
+// This fails if the registry already contains a builder with the same identifier.
+// Builders of parent registries can be overridden.
+func (r *Registry) RegisterChunk64(c Chunk64Builder) error {
+	id := c.Identifier()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, found := r.chunk64[id]; found {
+		return fmt.Errorf("Chunk64 with %s already exists", id)
+	}
+
+	r.chunk64[id] = c
+
+	return nil
+}
+
+// RegisterChunk32 adds the given Chunk32 to the registry.
+//
+// This fails if the registry already contains a builder with the same identifier.
+// Builders of parent registries can be overridden.
+func (r *Registry) RegisterChunk32(c Chunk32Builder) error {
+	id := c.Identifier()
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, found := r.chunk32[id]; found {
+		return fmt.Errorf("Chunk32 with %s already exists", id)
+	}
+
+	r.chunk32[id] = c
+
+	return nil
+}
+
+// Chunk64Builder returns the builder for the given identifier.
+// If the registry doesn't contain it, the parent registries are searched.
+func (r *Registry) Chunk64Builder(id Identifier64) (Chunk64Builder, bool) {
+	for ; r != nil; r = r.parent {
+		r.mutex.RLock()
+		c, found := r.chunk64[id]
+		r.mutex.RUnlock()
+		if found {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// Chunk32Builder returns the builder for the given identifier.
+// If the registry doesn't contain it, the parent registries are searched.
+func (r *Registry) Chunk32Builder(id Identifier32) (Chunk32Builder, bool) {
+	for ; r != nil; r = r.parent {
+		r.mutex.RLock()
+		c, found := r.chunk32[id]
+		r.mutex.RUnlock()
+		if found {
+			return c, true
+		}
+	}
+
+	return nil, false
+}
+
+// RegisterChunk64 adds the given Chunk64 to DefaultRegistry.
+func RegisterChunk64(c Chunk64Builder) error {
+	return DefaultRegistry.RegisterChunk64(c)
+}
+
+// MustRegisterChunk64 is similar to RegisterChunk64, but it panics on any error.
+func MustRegisterChunk64(c Chunk64Builder) {
+	if err := RegisterChunk64(c); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterChunk32 adds the given Chunk32 to DefaultRegistry.
+func RegisterChunk32(c Chunk32Builder) error {
+	return DefaultRegistry.RegisterChunk32(c)
+}
+
+// MustRegisterChunk32 is similar to RegisterChunk32, but it panics on any error.
+func MustRegisterChunk32(c Chunk32Builder) {
+	if err := RegisterChunk32(c); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// testChunkMXJVH264 replaces the builder of Chunk64MXJVH264.
+type testChunkMXJVH264 struct {
+	*mxriff64.Chunk64MXJVH264
+}
+
+func (testChunkMXJVH264) BuildChunk(a *mxriff64.Accessor) (mxriff64.Chunk64, error) {
+	c, err := (&mxriff64.Chunk64MXJVH264{}).BuildChunk(a)
+	if err != nil {
+		return nil, err
+	}
+	return testChunkMXJVH264{c.(*mxriff64.Chunk64MXJVH264)}, nil
+}
+
+// testChunkVFTE replaces the builder of Chunk32VFTE.
+type testChunkVFTE struct {
+	*mxriff64.Chunk32VFTE
+}
+
+func (testChunkVFTE) BuildChunk(a *mxriff64.Accessor) (mxriff64.Chunk32, error) {
+	c, err := (&mxriff64.Chunk32VFTE{}).BuildChunk(a)
+	if err != nil {
+		return nil, err
+	}
+	return testChunkVFTE{c.(*mxriff64.Chunk32VFTE)}, nil
+}
+
+func TestRegistry(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	// Returns the types of all visited chunks by their identifier.
+	chunkTypes := func(registry *mxriff64.Registry) map[string]any {
+		a := mxriff64.NewFromReaderAt(bytes.NewReader(data), int64(len(data)))
+		a.Registry = registry
+
+		types := map[string]any{}
+		err := mxriff64.Walk(a, func(entry mxriff64.WalkEntry) error {
+			types[entry.Identifier] = entry.Chunk
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to walk chunk tree: %v.", err)
+		}
+		return types
+	}
+
+	override := mxriff64.NewRegistry(mxriff64.DefaultRegistry)
+	if err := override.RegisterChunk64(testChunkMXJVH264{}); err != nil {
+		t.Fatalf("Failed to override Chunk64 builder: %v.", err)
+	}
+	if err := override.RegisterChunk64(testChunkMXJVH264{}); err == nil {
+		t.Errorf("Registering the same identifier twice in one registry succeeded.")
+	}
+
+	stacked := mxriff64.NewRegistry(override)
+	if err := stacked.RegisterChunk32(testChunkVFTE{}); err != nil {
+		t.Fatalf("Failed to override Chunk32 builder: %v.", err)
+	}
+
+	tests := []struct {
+		name     string
+		registry *mxriff64.Registry
+		wantH264 any
+		wantVFTE any
+	}{
+		{"Default", nil, &mxriff64.Chunk64MXJVH264{}, &mxriff64.Chunk32VFTE{}},
+		{"Override", override, testChunkMXJVH264{}, &mxriff64.Chunk32VFTE{}},
+		{"Stacked", stacked, testChunkMXJVH264{}, testChunkVFTE{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			types := chunkTypes(tt.registry)
+
+			if got, want := types["MXJVH264"], tt.wantH264; got == nil || want == nil || typeName(got) != typeName(want) {
+				t.Errorf("Unexpected MXJVH264 chunk type. Got %T, want %T.", got, want)
+			}
+			if got, want := types["VFTE"], tt.wantVFTE; typeName(got) != typeName(want) {
+				t.Errorf("Unexpected VFTE chunk type. Got %T, want %T.", got, want)
+			}
+		})
+	}
+
+	// Without any builders, even the root chunk is unknown.
+	if types := chunkTypes(mxriff64.NewRegistry(nil)); len(types) != 1 || typeName(types["MXRIFF64"]) != typeName(&mxriff64.Chunk64Dummy{}) {
+		t.Errorf("Unexpected chunks with empty registry: %v.", types)
+	}
+
+	// The default registry must not be changed by any of the above.
+	if c, ok := mxriff64.DefaultRegistry.Chunk64Builder(mxriff64.Identifier64{'M', 'X', 'J', 'V', 'H', '2', '6', '4'}); !ok {
+		t.Errorf("DefaultRegistry doesn't contain MXJVH264.")
+	} else if _, ok := c.(*mxriff64.Chunk64MXJVH264); !ok {
+		t.Errorf("DefaultRegistry contains builder %T for MXJVH264.", c)
+	}
+}
+
+// typeName returns the name of the dynamic type of v.
+func typeName(v any) string {
+	return fmt.Sprintf("%T", v)
+}
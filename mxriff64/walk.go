@@ -0,0 +1,114 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// ContainerChunk is implemented by all chunks that contain a list of sub-chunks, like Chunk64MXRIFF64, Chunk64MXLIST64 and Chunk64MXLIST32.
+type ContainerChunk interface {
+	Chunk64
+
+	// SubChunks returns an iterator listing all sub-chunks together with their file offsets.
+	//
+	// Any error is returned as the second value.
+	// In case there is an error, the iteration will stop.
+	SubChunks() iter.Seq2[SubChunk, error]
+}
+
+// SubChunk is a chunk inside of a ContainerChunk.
+type SubChunk struct {
+	Chunk  any   // The chunk, which is either a Chunk64 or a Chunk32.
+	Offset int64 // File offset of the chunk identifier.
+}
+
+// WalkEntry describes a chunk that is visited by Walk.
+type WalkEntry struct {
+	Chunk      any      // The chunk, which is either a Chunk64 or a Chunk32. Use a type switch to access its content.
+	Identifier string   // The identifier of the chunk, e.g. "MXLIST64".
+	Length     int64    // The total length of the chunk, including headers and such.
+	Offset     int64    // File offset of the chunk identifier.
+	Depth      int      // Nesting level of the chunk. The root chunk has a depth of 0.
+	Path       []string // Identifiers of all parent chunks and of the chunk itself, starting with the root chunk.
+}
+
+// WalkFunc is called by Walk for every chunk.
+//
+// If the function returns SkipChunk, the sub-chunks of the current chunk are not visited.
+// If the function returns SkipAll, the walk stops without an error.
+// Any other error stops the walk, and is returned by Walk.
+type WalkFunc func(entry WalkEntry) error
+
+// SkipChunk can be returned by a WalkFunc to skip the sub-chunks of the current chunk.
+var SkipChunk = errors.New("skip this chunk")
+
+// SkipAll can be returned by a WalkFunc to stop the walk.
+var SkipAll = errors.New("skip everything and stop the walk")
+
+// Walk reads the chunk at the current position of "a", and calls visit for it and for all its sub-chunks, depth-first in file order.
+//
+// Sub-chunks are only visited for chunks that implement ContainerChunk.
+// Unknown chunks are visited as Chunk64Dummy or Chunk32Dummy.
+func Walk(a *Accessor, visit WalkFunc) error {
+	offset := a.Pos
+
+	chunk, err := a.ReadChunk64()
+	if err != nil {
+		return fmt.Errorf("failed to read chunk at offset %d: %w", offset, err)
+	}
+
+	if err := walk(chunk, offset, nil, visit); err != nil && err != SkipAll {
+		return err
+	}
+
+	return nil
+}
+
+// walk visits the given chunk and all its sub-chunks.
+// parentPath contains the identifiers of all parent chunks.
+func walk(chunk any, offset int64, parentPath []string, visit WalkFunc) error {
+	entry := WalkEntry{
+		Chunk:  chunk,
+		Offset: offset,
+		Depth:  len(parentPath),
+	}
+	switch chunk := chunk.(type) {
+	case Chunk64:
+		entry.Identifier, entry.Length = chunk.Identifier().name(), chunk.Length()
+	case Chunk32:
+		entry.Identifier, entry.Length = chunk.Identifier().name(), int64(chunk.Length())
+	default:
+		return fmt.Errorf("invalid chunk type %T at offset %d", chunk, offset)
+	}
+	entry.Path = append(slices.Clip(parentPath), entry.Identifier)
+
+	if err := visit(entry); err != nil {
+		if err == SkipChunk {
+			return nil
+		}
+		return err
+	}
+
+	container, ok := chunk.(ContainerChunk)
+	if !ok {
+		return nil
+	}
+
+	for sc, err := range container.SubChunks() {
+		if err != nil {
+			return fmt.Errorf("failed to read sub-chunk of %q chunk at offset %d: %w", entry.Identifier, offset, err)
+		}
+		if err := walk(sc.Chunk, sc.Offset, entry.Path, visit); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
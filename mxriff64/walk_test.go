@@ -0,0 +1,120 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+func TestWalk(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	walk := func(visit mxriff64.WalkFunc) error {
+		return mxriff64.Walk(mxriff64.NewFromReadSeeker(bytes.NewReader(data)), visit)
+	}
+
+	t.Run("All", func(t *testing.T) {
+		var entries []mxriff64.WalkEntry
+		var chunk32s int
+		err := walk(func(entry mxriff64.WalkEntry) error {
+			if _, ok := entry.Chunk.(mxriff64.Chunk32); ok {
+				chunk32s++
+			}
+			entries = append(entries, entry)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to walk chunk tree: %v.", err)
+		}
+
+		if len(entries) == 0 || entries[0].Identifier != "MXRIFF64" || entries[0].Offset != 0 || entries[0].Depth != 0 || entries[0].Length != int64(len(data)) {
+			t.Fatalf("Unexpected root entry: %+v.", entries)
+		}
+		if chunk32s == 0 {
+			t.Errorf("No Chunk32 found.")
+		}
+
+		for i, entry := range entries {
+			if got := string(data[entry.Offset : entry.Offset+int64(len(entry.Identifier))]); got != entry.Identifier {
+				t.Errorf("Entry %d has identifier %q in the file, want %q.", i, got, entry.Identifier)
+			}
+			if len(entry.Path) != entry.Depth+1 || entry.Path[entry.Depth] != entry.Identifier {
+				t.Errorf("Entry %d has an unexpected path %v.", i, entry.Path)
+			}
+			if i > 0 && entry.Depth > entries[i-1].Depth && !slices.Equal(entry.Path[:entry.Depth], entries[i-1].Path) {
+				t.Errorf("Path %v of entry %d doesn't continue the path %v of its parent.", entry.Path, i, entries[i-1].Path)
+			}
+		}
+
+		// The video frames are stored in the second level.
+		if !slices.ContainsFunc(entries, func(e mxriff64.WalkEntry) bool {
+			return strings.Join(e.Path, "/") == "MXRIFF64/MXLIST64/MXJVVF64"
+		}) {
+			t.Errorf("No video frame found.")
+		}
+	})
+
+	t.Run("SkipChunk", func(t *testing.T) {
+		var visited int
+		err := walk(func(entry mxriff64.WalkEntry) error {
+			visited++
+			if entry.Depth > 1 {
+				t.Errorf("Visited skipped chunk %v.", entry.Path)
+			}
+			if entry.Depth == 1 {
+				return mxriff64.SkipChunk
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to walk chunk tree: %v.", err)
+		}
+		if visited < 2 {
+			t.Errorf("Visited %d chunks, want at least 2.", visited)
+		}
+	})
+
+	t.Run("SkipAll", func(t *testing.T) {
+		var visited int
+		err := walk(func(entry mxriff64.WalkEntry) error {
+			visited++
+			if visited == 3 {
+				return mxriff64.SkipAll
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Failed to walk chunk tree: %v.", err)
+		}
+		if visited != 3 {
+			t.Errorf("Visited %d chunks, want %d.", visited, 3)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		errTest := errors.New("test error")
+		err := walk(func(entry mxriff64.WalkEntry) error {
+			if entry.Depth == 1 {
+				return errTest
+			}
+			return nil
+		})
+		if !errors.Is(err, errTest) {
+			t.Errorf("Unexpected error %v, want %v.", err, errTest)
+		}
+	})
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxriff64_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// TestWriteChunkCopy reads an example file and writes every chunk into a new file.
+// The result has to be byte for byte identical to the original.
+func TestWriteChunkCopy(t *testing.T) {
+	srcFilename := filepath.Join("..", "example-files", "25i.mxv")
+	src, err := os.Open(srcFilename)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer src.Close()
+
+	dstFilename := filepath.Join(t.TempDir(), "copy.mxv")
+	dst, err := os.Create(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer dst.Close()
+
+	srcAccessor, dstAccessor := mxriff64.NewFromReadSeeker(src), mxriff64.NewFromWriteSeeker(dst)
+
+	// Copies the payload of a chunk and finishes it.
+	copyPayload := func(w io.Closer, r io.Reader, err error) {
+		if err != nil {
+			t.Fatalf("Failed to get data reader: %v.", err)
+		}
+		if _, err := io.Copy(dstAccessor, r); err != nil {
+			t.Fatalf("Failed to copy chunk data: %v.", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Failed to close chunk: %v.", err)
+		}
+	}
+
+	var copyChunk func(chunk any)
+	copyChunk = func(chunk any) {
+		// Copy the chunk objects, as WriteChunk will replace the accessor.
+		switch chunk := chunk.(type) {
+		case *mxriff64.Chunk64MXRIFF64:
+			c := *chunk
+			if err := c.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+			for sc, err := range chunk.Chunks() {
+				if err != nil {
+					t.Fatalf("Failed to read sub-chunk: %v.", err)
+				}
+				copyChunk(sc)
+			}
+			if err := c.Close(); err != nil {
+				t.Fatalf("Failed to close chunk: %v.", err)
+			}
+		case *mxriff64.Chunk64MXLIST64:
+			c := *chunk
+			if err := c.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+			for sc, err := range chunk.Chunks() {
+				if err != nil {
+					t.Fatalf("Failed to read sub-chunk: %v.", err)
+				}
+				copyChunk(sc)
+			}
+			if err := c.Close(); err != nil {
+				t.Fatalf("Failed to close chunk: %v.", err)
+			}
+		case *mxriff64.Chunk64MXLIST32:
+			c := *chunk
+			if err := c.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+			for sc, err := range chunk.Chunks() {
+				if err != nil {
+					t.Fatalf("Failed to read sub-chunk: %v.", err)
+				}
+				copyChunk(sc)
+			}
+			if err := c.Close(); err != nil {
+				t.Fatalf("Failed to close chunk: %v.", err)
+			}
+		case *mxriff64.Chunk64MXJVVF64:
+			c := *chunk
+			if err := c.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+			r, err := chunk.DataReader()
+			copyPayload(&c, r, err)
+		case *mxriff64.Chunk64MXJVAF64:
+			c := *chunk
+			if err := c.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+			r, err := chunk.DataReader()
+			copyPayload(&c, r, err)
+		case *mxriff64.Chunk64MXJVFT64:
+			c := *chunk
+			if err := c.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+			r, err := chunk.DataReader()
+			copyPayload(&c, r, err)
+		case *mxriff64.Chunk64Dummy:
+			c := *chunk
+			if err := c.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+			r, err := chunk.DataReader()
+			copyPayload(&c, r, err)
+		case mxriff64.Chunk64Writer:
+			if err := chunk.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+		case mxriff64.Chunk32Writer:
+			if err := chunk.WriteChunk(dstAccessor); err != nil {
+				t.Fatalf("Failed to write chunk: %v.", err)
+			}
+		default:
+			t.Fatalf("Invalid object %T passed to copyChunk.", chunk)
+		}
+	}
+
+	c, err := srcAccessor.ReadChunk64()
+	if err != nil {
+		t.Fatalf("Failed to read Chunk64: %v.", err)
+	}
+	copyChunk(c)
+
+	want, err := os.ReadFile(srcFilename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+	got, err := os.ReadFile(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Copied container differs from the original. Got %d bytes, want %d bytes.", len(got), len(want))
+	}
+}
+
+// TestWriteChunkBackPatch checks whether the DataLength fields of nested chunks are back-patched correctly.
+func TestWriteChunkBackPatch(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.mxv")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	a := mxriff64.NewFromWriteSeeker(f)
+
+	root := &mxriff64.Chunk64MXRIFF64{}
+	root.Header.FormType = mxriff64.FormTypeMXJVID64
+	if err := root.WriteChunk(a); err != nil {
+		t.Fatalf("Failed to write chunk: %v.", err)
+	}
+
+	list := &mxriff64.Chunk64MXLIST64{}
+	list.Header.ContentType = mxriff64.ContentTypeMXJVFL64
+	if err := list.WriteChunk(a); err != nil {
+		t.Fatalf("Failed to write chunk: %v.", err)
+	}
+
+	audioData := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	audio := &mxriff64.Chunk64MXJVAF64{Data: mxriff64.Chunk64MXJVAF64Data{ChannelBitDepth: 16, StartSample: 0, Samples: 2}}
+	if err := audio.WriteChunk(a); err != nil {
+		t.Fatalf("Failed to write chunk: %v.", err)
+	}
+	if _, err := a.Write(audioData); err != nil {
+		t.Fatalf("Failed to write audio data: %v.", err)
+	}
+	if err := audio.Close(); err != nil {
+		t.Fatalf("Failed to close chunk: %v.", err)
+	}
+
+	if err := list.Close(); err != nil {
+		t.Fatalf("Failed to close chunk: %v.", err)
+	}
+
+	lookupList := &mxriff64.Chunk64MXLIST32{}
+	lookupList.Header.ContentType = mxriff64.ContentTypeMXJVTL32
+	if err := lookupList.WriteChunk(a); err != nil {
+		t.Fatalf("Failed to write chunk: %v.", err)
+	}
+	afte := &mxriff64.Chunk32AFTE{Data: mxriff64.Chunk32AFTEData{AudioFrameChunkOffset: 48, AudioFrameChunkSize: uint32(audio.Length()), StartSample: 0, Samples: 2}}
+	if err := afte.WriteChunk(a); err != nil {
+		t.Fatalf("Failed to write chunk: %v.", err)
+	}
+	if err := lookupList.Close(); err != nil {
+		t.Fatalf("Failed to close chunk: %v.", err)
+	}
+
+	if err := root.Close(); err != nil {
+		t.Fatalf("Failed to close chunk: %v.", err)
+	}
+
+	// Check the header values directly.
+	var header struct {
+		ID         mxriff64.Identifier64
+		DataLength int64
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v.", err)
+	}
+	if err := binary.Read(f, binary.LittleEndian, &header); err != nil {
+		t.Fatalf("Failed to read header: %v.", err)
+	}
+	if header.DataLength != a.Pos-24 {
+		t.Errorf("Root chunk has wrong DataLength. Got %d, want %d.", header.DataLength, a.Pos-24)
+	}
+
+	// Read everything back.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v.", err)
+	}
+	c, err := mxriff64.NewFromReadSeeker(f).ReadChunk64()
+	if err != nil {
+		t.Fatalf("Failed to read Chunk64: %v.", err)
+	}
+	rootRead, ok := c.(*mxriff64.Chunk64MXRIFF64)
+	if !ok {
+		t.Fatalf("Invalid root chunk type. Got %T, want %T.", c, rootRead)
+	}
+
+	var chunks int
+	for sc, err := range rootRead.Chunks() {
+		if err != nil {
+			t.Fatalf("Failed to read sub-chunk: %v.", err)
+		}
+		chunks++
+
+		switch sc := sc.(type) {
+		case *mxriff64.Chunk64MXLIST64:
+			for scc, err := range sc.Chunks() {
+				if err != nil {
+					t.Fatalf("Failed to read sub-chunk: %v.", err)
+				}
+				audioRead, ok := scc.(*mxriff64.Chunk64MXJVAF64)
+				if !ok {
+					t.Fatalf("Invalid chunk type. Got %T, want %T.", scc, audioRead)
+				}
+				if audioRead.Data != audio.Data {
+					t.Errorf("Audio frame header differs. Got %+v, want %+v.", audioRead.Data, audio.Data)
+				}
+				r, err := audioRead.DataReader()
+				if err != nil {
+					t.Fatalf("Failed to get data reader: %v.", err)
+				}
+				if got, err := io.ReadAll(r); err != nil {
+					t.Fatalf("Failed to read audio data: %v.", err)
+				} else if !bytes.Equal(got, audioData) {
+					t.Errorf("Audio data differs. Got %v, want %v.", got, audioData)
+				}
+			}
+		case *mxriff64.Chunk64MXLIST32:
+			for scc, err := range sc.Chunks() {
+				if err != nil {
+					t.Fatalf("Failed to read sub-chunk: %v.", err)
+				}
+				afteRead, ok := scc.(*mxriff64.Chunk32AFTE)
+				if !ok {
+					t.Fatalf("Invalid chunk type. Got %T, want %T.", scc, afteRead)
+				}
+				if afteRead.Data != afte.Data {
+					t.Errorf("AFTE entry differs. Got %+v, want %+v.", afteRead.Data, afte.Data)
+				}
+			}
+		default:
+			t.Errorf("Unexpected sub-chunk %T.", sc)
+		}
+	}
+	if chunks != 2 {
+		t.Errorf("Unexpected number of sub-chunks. Got %d, want %d.", chunks, 2)
+	}
+}
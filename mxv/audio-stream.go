@@ -0,0 +1,192 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// AudioStream provides access to the continuous raw audio data of all audio frames.
+// The encoding of the data is defined in Info.AudioFormat.
+//
+// Every audio frame is placed at its StartSample, so byte offset 0 is the first byte of sample 0.
+// Gaps between audio frames are filled with silence, and samples that overlap with a previous audio frame are skipped.
+// This results in the same data as PolicyRepair for CheckAudioContinuity, even if the problems weren't repaired by the reader.
+// Frame boundaries are handled internally.
+//
+// If the reader was created with NewReaderAt, ReadAt can be used from several goroutines at once.
+// Read and Seek share the current stream position, and must not be used concurrently.
+type AudioStream struct {
+	r *Reader
+
+	bytesPerSample int64
+	segments       []audioSegment // Audio frames and silence in stream order.
+	size           int64          // The length of the stream in bytes.
+
+	pos int64 // Current stream offset for Read and Seek.
+}
+
+// audioSegment is a contiguous part of an AudioStream that is either read from an audio frame, or is silence.
+type audioSegment struct {
+	offset int64 // Stream offset of the segment.
+	frame  int   // The audio frame, or -1 for silence.
+	skip   int64 // Number of bytes at the start of the audio frame that are not part of the stream.
+}
+
+// AudioStream returns a new stream over the raw audio data of all audio frames.
+//
+// Every returned stream has its own position, so several streams can be used independently.
+func (r *Reader) AudioStream() (*AudioStream, error) {
+	if err := r.PrepareLookupTable(); err != nil {
+		return nil, fmt.Errorf("failed to prepare frame chunk lookup table: %w", err)
+	}
+
+	if !r.Info.HasAudio || r.Info.AudioBytesPerSample == 0 {
+		return nil, ErrNoAudio
+	}
+
+	audioFrameOffsets := r.audioFrameOffsets
+
+	s := &AudioStream{
+		r:              r,
+		bytesPerSample: int64(r.Info.AudioBytesPerSample),
+		segments:       make([]audioSegment, 0, len(audioFrameOffsets)),
+	}
+
+	// The audio frames are sorted by their start sample.
+	for frame, afte := range audioFrameOffsets {
+		start := int64(afte.StartSample) * s.bytesPerSample
+		end := start + int64(afte.Samples)*s.bytesPerSample
+		if start > s.size {
+			s.segments = append(s.segments, audioSegment{offset: s.size, frame: -1})
+			s.size = start
+		}
+		if end <= s.size {
+			// The frame is completely covered by previous frames.
+			continue
+		}
+		s.segments = append(s.segments, audioSegment{offset: s.size, frame: frame, skip: s.size - start})
+		s.size = end
+	}
+
+	return s, nil
+}
+
+// Size returns the length of the audio stream in bytes.
+func (s *AudioStream) Size() int64 {
+	return s.size
+}
+
+// Samples returns the length of the audio stream in samples.
+// A sample contains the data of all channels.
+func (s *AudioStream) Samples() uint64 {
+	return uint64(s.Size() / s.bytesPerSample)
+}
+
+// Read reads the audio data at the current stream position.
+// A single call will not read beyond the end of an audio frame.
+func (s *AudioStream) Read(p []byte) (int, error) {
+	n, err := s.readFrameAt(p, s.pos)
+	s.pos += int64(n)
+	return n, err
+}
+
+// ReadAt reads len(p) bytes of audio data starting at the given stream offset.
+// It doesn't use or change the current stream position.
+func (s *AudioStream) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+
+	var n int
+	for n < len(p) {
+		m, err := s.readFrameAt(p[n:], off+int64(n))
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// Seek sets the stream position for the next Read to the given byte offset, interpreted according to whence.
+func (s *AudioStream) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += s.pos
+	case io.SeekEnd:
+		offset += s.Size()
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative stream position %d", offset)
+	}
+
+	s.pos = offset
+	return offset, nil
+}
+
+// SeekSample sets the stream position for the next Read to the start of the given sample.
+// Samples are counted like the StartSample field of the audio frames, so sample 0 is the start of the audio, even if the first audio frame starts later.
+func (s *AudioStream) SeekSample(sample uint64) (int64, error) {
+	return s.Seek(int64(sample)*s.bytesPerSample, io.SeekStart)
+}
+
+// readFrameAt reads audio data at the given stream offset into p.
+// It reads at most until the end of the audio frame or silence that contains the offset.
+func (s *AudioStream) readFrameAt(p []byte, off int64) (int, error) {
+	if off >= s.Size() {
+		return 0, io.EOF
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// Find the last segment that starts at or before the offset.
+	// Empty frames are skipped, as they start at the same offset as the next segment.
+	i := sort.Search(len(s.segments), func(i int) bool { return s.segments[i].offset > off }) - 1
+	segment, segmentEnd := s.segments[i], s.size
+	if i+1 < len(s.segments) {
+		segmentEnd = s.segments[i+1].offset
+	}
+	p = p[:min(int64(len(p)), segmentEnd-off)]
+
+	if segment.frame < 0 {
+		return s.r.silenceValue().Read(p)
+	}
+
+	frame := segment.frame
+	reader, _, samples, err := s.r.AudioFrameData(frame)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get audio data stream of frame %d: %w", frame, err)
+	}
+
+	if skip := off - segment.offset + segment.skip; skip > 0 {
+		if seeker, ok := reader.(io.Seeker); ok {
+			if _, err := seeker.Seek(skip, io.SeekCurrent); err != nil {
+				return 0, fmt.Errorf("failed to seek in audio frame %d: %w", frame, err)
+			}
+		} else if _, err := io.CopyN(io.Discard, reader, skip); err != nil {
+			return 0, fmt.Errorf("failed to skip %d bytes of audio frame %d: %w", skip, frame, err)
+		}
+	}
+
+	n, err := io.ReadFull(reader, p)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, fmt.Errorf("audio frame %d is shorter than its %d samples: %w", frame, samples, io.ErrUnexpectedEOF)
+	}
+	if err != nil {
+		return n, fmt.Errorf("failed to read audio frame %d: %w", frame, err)
+	}
+
+	return n, nil
+}
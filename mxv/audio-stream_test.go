@@ -0,0 +1,111 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+func TestAudioStream(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "23.976p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	for _, mode := range []string{"ReadSeeker", "ReaderAt"} {
+		t.Run(mode, func(t *testing.T) {
+			var mxvReader *mxv.Reader
+			if mode == "ReaderAt" {
+				mxvReader, err = mxv.NewReaderAt(bytes.NewReader(data), int64(len(data)))
+			} else {
+				mxvReader, err = mxv.NewReader(bytes.NewReader(data))
+			}
+			if err != nil {
+				t.Fatalf("Failed to read MXV file: %v.", err)
+			}
+
+			// Get the expected audio data by concatenating all frames.
+			var want []byte
+			for frame := range mxvReader.AudioFrames() {
+				r, _, _, err := mxvReader.AudioFrameData(frame)
+				if err != nil {
+					t.Fatalf("Failed to get audio data stream: %v.", err)
+				}
+				frameData, err := io.ReadAll(r)
+				if err != nil {
+					t.Fatalf("Failed to read audio data stream: %v.", err)
+				}
+				want = append(want, frameData...)
+			}
+
+			stream, err := mxvReader.AudioStream()
+			if err != nil {
+				t.Fatalf("Failed to get audio stream: %v.", err)
+			}
+			if got := stream.Size(); got != int64(len(want)) {
+				t.Errorf("Unexpected stream size. Got %d, want %d.", got, len(want))
+			}
+			if got := stream.Samples(); got != mxvReader.Info.AudioSamples {
+				t.Errorf("Unexpected number of samples. Got %d, want %d.", got, mxvReader.Info.AudioSamples)
+			}
+
+			got, err := io.ReadAll(stream)
+			if err != nil {
+				t.Fatalf("Failed to read audio stream: %v.", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Audio stream differs from the audio frames. Got %d bytes, want %d bytes.", len(got), len(want))
+			}
+
+			// Read ranges that cross frame boundaries.
+			bytesPerSample := int64(mxvReader.Info.AudioBytesPerSample)
+			for _, sample := range []uint64{0, 1, 1000, 2001, 50000, mxvReader.Info.AudioSamples - 10} {
+				offset, err := stream.SeekSample(sample)
+				if err != nil {
+					t.Fatalf("Failed to seek to sample %d: %v.", sample, err)
+				}
+				if offset != int64(sample)*bytesPerSample {
+					t.Errorf("Seeking to sample %d resulted in offset %d, want %d.", sample, offset, int64(sample)*bytesPerSample)
+				}
+
+				length := min(5000, int64(len(want))-offset)
+				buf := make([]byte, length)
+				if _, err := io.ReadFull(stream, buf); err != nil {
+					t.Fatalf("Failed to read at sample %d: %v.", sample, err)
+				}
+				if !bytes.Equal(buf, want[offset:offset+length]) {
+					t.Errorf("Read at sample %d differs from the audio frames.", sample)
+				}
+
+				clear(buf)
+				if _, err := stream.ReadAt(buf, offset); err != nil {
+					t.Fatalf("Failed to read at offset %d: %v.", offset, err)
+				}
+				if !bytes.Equal(buf, want[offset:offset+length]) {
+					t.Errorf("ReadAt at offset %d differs from the audio frames.", offset)
+				}
+			}
+
+			// Reading beyond the end.
+			buf := make([]byte, 100)
+			if n, err := stream.ReadAt(buf, stream.Size()-10); n != 10 || err != io.EOF {
+				t.Errorf("Unexpected result of reading beyond the end. Got %d, %v, want %d, %v.", n, err, 10, io.EOF)
+			}
+			if _, err := stream.Seek(0, io.SeekEnd); err != nil {
+				t.Fatalf("Failed to seek to the end: %v.", err)
+			}
+			if n, err := stream.Read(buf); n != 0 || err != io.EOF {
+				t.Errorf("Unexpected result of reading at the end. Got %d, %v, want %d, %v.", n, err, 0, io.EOF)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv
+
+import (
+	"slices"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// ChunkInfo describes a top-level chunk of the MXRIFF64 container.
+type ChunkInfo struct {
+	Identifier mxriff64.Identifier64
+	Offset     int64 // File offset of the chunk identifier.
+	Length     int64 // Total length of the chunk, including its headers.
+
+	// The parsed chunk. Use a type switch to access its header and data, e.g. *mxriff64.Chunk64MXJVH264.
+	// Chunks with an unknown identifier, and MXJVCO64 or MXJVPD64 chunks that are too short to be parsed, are of type *mxriff64.Chunk64Dummy.
+	//
+	// Reading any data of the chunk shares the file offset with the reader, see Reader.
+	Chunk mxriff64.Chunk64
+}
+
+// Chunks returns all top-level chunks of the file in file order.
+//
+// In recovery mode, this only contains the chunks up to the first damaged one.
+func (r *Reader) Chunks() []ChunkInfo {
+	return slices.Clone(r.chunks)
+}
+
+// RawHeaders contains the data of all header chunks as they are stored in the file, including fields with unknown meaning.
+// The fields are nil if the file doesn't contain the respective chunk, or if the MXJVCO64 or MXJVPD64 chunk is too short to be parsed.
+type RawHeaders struct {
+	MXJVH264 *mxriff64.Chunk64MXJVH264Data
+	MXJVHD64 *mxriff64.Chunk64MXJVHD64Data
+	MXWFMT64 *mxriff64.Chunk64MXWFMT64Data
+	MXJVCO64 *mxriff64.Chunk64MXJVCO64Data
+	MXJVPD64 *mxriff64.Chunk64MXJVPD64Data
+}
+
+// RawHeaders returns a copy of the data of all header chunks.
+//
+// Info contains the interpreted values of these headers, use this to inspect the fields that Info doesn't contain.
+func (r *Reader) RawHeaders() RawHeaders {
+	var headers RawHeaders
+
+	if r.chunkVideoHeader2 != nil {
+		data := r.chunkVideoHeader2.Data
+		headers.MXJVH264 = &data
+	}
+	if r.chunkVideoHeader != nil {
+		data := r.chunkVideoHeader.Data
+		headers.MXJVHD64 = &data
+	}
+	if r.chunkWaveFormat != nil {
+		data := r.chunkWaveFormat.Data
+		headers.MXWFMT64 = &data
+	}
+	if r.chunkCO64 != nil {
+		data := r.chunkCO64.Data
+		headers.MXJVCO64 = &data
+	}
+	if r.chunkPD64 != nil {
+		data := r.chunkPD64.Data
+		headers.MXJVPD64 = &data
+	}
+
+	return headers
+}
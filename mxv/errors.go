@@ -0,0 +1,77 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// Sentinel errors that describe the kind of a problem.
+// They are wrapped by the returned errors, use errors.Is to check for them.
+var (
+	ErrHeaderMismatch   = errors.New("the video headers contain contradicting information")
+	ErrVideoFrameCount  = errors.New("the number of video frames differs from the header")
+	ErrAudioFrameCount  = errors.New("the number of audio frames differs from the header")
+	ErrAudioSampleCount = errors.New("the number of audio samples differs from the header")
+	ErrAudioGap         = errors.New("there is a gap in the audio data")
+	ErrAudioOverlap     = errors.New("there is an overlap in the audio data")
+	ErrChunkSize        = errors.New("the chunk size differs from the lookup entry")
+	ErrLookupList       = errors.New("the lookup list is missing or damaged")
+	ErrFrameChunk       = errors.New("the lookup entry doesn't point to a frame chunk")
+	ErrFrameRange       = errors.New("the frame number is out of range")
+	ErrMissingChunk     = errors.New("couldn't find required chunk")
+	ErrNoAudio          = errors.New("the file doesn't contain any audio")
+
+	ErrTruncated    = mxriff64.ErrTruncated    // A chunk extends beyond the end of the file. Same as mxriff64.ErrTruncated.
+	ErrChunkOverrun = mxriff64.ErrChunkOverrun // A chunk extends beyond its parent chunk. Same as mxriff64.ErrChunkOverrun.
+)
+
+// CheckError is returned when one of the checks finds a problem, and the policy of the check is PolicyFail.
+type CheckError struct {
+	Err        error  // The kind of the problem, e.g. ErrAudioGap.
+	Check      Check  // The check that found the problem.
+	Identifier string // The identifier of the chunk the problem refers to, or empty if it doesn't refer to a specific chunk.
+	Offset     int64  // File offset of the chunk the problem refers to, or -1 if it doesn't refer to a specific chunk.
+	Frame      int    // The video or audio frame the problem refers to, or -1 if it doesn't refer to a specific frame.
+	Expected   any    // The expected value, or nil if there is none.
+	Actual     any    // The actual value, or nil if there is none.
+	Message    string
+}
+
+func (e *CheckError) Error() string {
+	return e.Message
+}
+
+func (e *CheckError) Unwrap() error {
+	return e.Err
+}
+
+// FrameError is returned when the data of a video or audio frame can't be accessed.
+type FrameError struct {
+	Identifier string // The identifier of the frame chunk, which is "MXJVVF64" for video and "MXJVAF64" for audio frames.
+	Frame      int    // The requested frame.
+	Offset     int64  // File offset of the frame chunk, or -1 if it is unknown.
+	Err        error  // The cause, e.g. ErrFrameRange, or a *mxriff64.ChunkLengthError.
+}
+
+func (e *FrameError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("%s chunk of frame %d: %v", e.Identifier, e.Frame, e.Err)
+	}
+	return fmt.Sprintf("%s chunk of frame %d at offset %d: %v", e.Identifier, e.Frame, e.Offset, e.Err)
+}
+
+func (e *FrameError) Unwrap() error {
+	return e.Err
+}
+
+// frameRangeError returns a *FrameError for a frame that is outside of [0...frames-1].
+func frameRangeError(identifier string, frame, frames int) error {
+	return &FrameError{Identifier: identifier, Frame: frame, Offset: -1, Err: fmt.Errorf("%w: valid range is from %d to %d", ErrFrameRange, 0, frames-1)}
+}
@@ -1,15 +1,30 @@
 package mxv
 
-import "github.com/Dadido3/mxv-demuxer/mxriff64"
+import (
+	"math"
+	"math/bits"
+
+	"github.com/Dadido3/mxv-demuxer/internal/fraction"
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
 
 // Info contains information about the video and audio data of a MXV file.
 type Info struct {
+	VideoHeader mxriff64.Identifier64 // Identifier of the video header chunk the information is taken from. Either MXJVH264, or MXJVHD64 for older files.
+
 	ColorFormat mxriff64.ColorFormat
 	FrameWidth  uint32
 	FrameHeight uint32
-	Framerate   float64 // Rate of frame/s.
-	VideoFrames uint64  // Total amount of video frames.
-	AspectRatio float64 // Output aspect ratio. The final video needs to be stretched to this ratio.
+	Framerate   float64 // Rate of frame/s, as stored in the video header. This is rounded for NTSC rates like 29.97.
+	// Exact framerate as fraction FramerateNum/FramerateDen in frame/s, e.g. 30000/1001.
+	// This is recovered from Framerate and the audio sample count, see FramerateFraction.
+	FramerateNum uint32
+	FramerateDen uint32
+	VideoFrames  uint64              // Total amount of video frames.
+	AspectRatio  float64             // Output aspect ratio. The final video needs to be stretched to this ratio.
+	Flags        mxriff64.VideoFlags // Raw flags of the video header, including unknown bits. HasAudio, Interlaced and FieldOrder are decoded from them.
+	Interlaced   bool                // The frames contain two interleaved fields. This is unreliable, as it is decoded from the guessed mxriff64.VideoFlagInterlaced, which known interlaced files don't have set.
+	FieldOrder   mxriff64.FieldOrder // The field that is displayed first. Only meaningful if Interlaced is set. Just as unreliable as Interlaced.
 
 	HasAudio             bool
 	AudioFormat          mxriff64.AudioFormat
@@ -21,3 +36,48 @@ type Info struct {
 	AudioFrames          uint64
 	AudioSamples         uint64
 }
+
+// FramerateFraction returns the exact framerate as fraction num/den in frame/s.
+//
+// If FramerateNum and FramerateDen are not set, the fraction is approximated from Framerate.
+// Both values fit into an uint32.
+func (i Info) FramerateFraction() (num, den uint64) {
+	if i.FramerateNum != 0 && i.FramerateDen != 0 {
+		return uint64(i.FramerateNum), uint64(i.FramerateDen)
+	}
+
+	num, den = fraction.Approximate(i.Framerate, 1001)
+	if num > math.MaxUint32 {
+		num, den = uint64(math.Round(min(i.Framerate, math.MaxUint32/1000)*1000)), 1000
+	}
+	return num, den
+}
+
+// VideoFrameTimestamp returns the presentation timestamp and duration of the given video frame in units of 1/timescale seconds.
+// The frame doesn't have to exist, so this can also be used to get the end of the last frame.
+//
+// The timestamps are calculated from the exact framerate and rounded to the nearest unit.
+// The durations are the differences between consecutive timestamps, so they add up without drift.
+func (i Info) VideoFrameTimestamp(frame, timescale uint64) (pts, duration uint64) {
+	num, den := i.FramerateFraction()
+	pts = scaleRounded(frame, den*timescale, num)
+	return pts, scaleRounded(frame+1, den*timescale, num) - pts
+}
+
+// scaleRounded returns a*b/c rounded to the nearest integer without intermediate overflow.
+// The result saturates at math.MaxUint64.
+func scaleRounded(a, b, c uint64) uint64 {
+	if c == 0 {
+		return math.MaxUint64
+	}
+
+	hi, lo := bits.Mul64(a, b)
+	lo, carry := bits.Add64(lo, c/2, 0)
+	hi += carry
+	if hi >= c {
+		return math.MaxUint64
+	}
+
+	quo, _ := bits.Div64(hi, lo, c)
+	return quo
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// Policy defines how the reader handles a problem found by one of its checks.
+type Policy int
+
+const (
+	PolicyFail   Policy = iota // Return an error. This is the default for all checks.
+	PolicyWarn                 // Record a warning, and continue with the data as it is.
+	PolicyRepair               // Record a warning, and repair the problem if possible. Otherwise this behaves like PolicyWarn.
+)
+
+func (p Policy) String() string {
+	switch p {
+	case PolicyFail:
+		return "fail"
+	case PolicyWarn:
+		return "warn"
+	case PolicyRepair:
+		return "repair"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// policy returns the policy that is set for the given check.
+func (o ReaderOptions) policy(check Check) Policy {
+	return o.Policies[check] // Unset checks return the zero value, which is PolicyFail.
+}
+
+// handle applies the policy of the check of the given problem.
+//
+// For PolicyFail the problem is returned as *CheckError with the given message.
+// Otherwise the problem is recorded as warning, and the policy is returned.
+func (r *Reader) handle(problem CheckError, format string, a ...any) (Policy, error) {
+	problem.Message = fmt.Sprintf(format, a...)
+
+	policy := r.options.policy(problem.Check)
+	if policy == PolicyFail {
+		return policy, &problem
+	}
+
+	r.warn(problem)
+	return policy, nil
+}
+
+// warn records the given problem as warning, independent of any policy.
+func (r *Reader) warn(problem CheckError) {
+	r.warningsMu.Lock()
+	defer r.warningsMu.Unlock()
+
+	r.warnings.add(SeverityWarning, problem.Check, problem.Offset, problem.Expected, problem.Actual, "%s", problem.Message)
+}
+
+// Warnings returns all problems that were ignored or repaired because of the policies in ReaderOptions.
+func (r *Reader) Warnings() []Finding {
+	r.warningsMu.Lock()
+	defer r.warningsMu.Unlock()
+
+	return slices.Clone(r.warnings.Findings)
+}
+
+// handleAudioContinuity checks the given audio frames for gaps and overlaps, and handles them according to the policy of CheckAudioContinuity.
+// The audio frames have to be sorted by their start sample.
+//
+// With PolicyRepair, gaps are filled with silence frames, and overlapping samples are trimmed from the start of the later frame.
+// Silence frames have an AudioFrameChunkOffset of -1.
+// The number of trimmed samples is stored in r.audioFrameTrims.
+func (r *Reader) handleAudioContinuity(aftes []mxriff64.Chunk32AFTEData) ([]mxriff64.Chunk32AFTEData, error) {
+	var result []mxriff64.Chunk32AFTEData
+	var audioSamples uint64
+	for frame, afte := range aftes {
+		switch {
+		case afte.StartSample > audioSamples:
+			policy, err := r.handle(CheckError{Err: ErrAudioGap, Check: CheckAudioContinuity, Identifier: "MXJVAF64", Offset: afte.AudioFrameChunkOffset, Frame: frame, Expected: audioSamples, Actual: afte.StartSample}, "there is a gap of %d samples before audio frame %d", afte.StartSample-audioSamples, frame)
+			if err != nil {
+				return nil, err
+			}
+			if policy == PolicyRepair {
+				for gap := afte.StartSample - audioSamples; gap > 0; {
+					samples := uint32(min(gap, uint64(^uint32(0))))
+					result = append(result, mxriff64.Chunk32AFTEData{AudioFrameChunkOffset: -1, StartSample: audioSamples, Samples: samples})
+					audioSamples += uint64(samples)
+					gap -= uint64(samples)
+				}
+			}
+		case afte.StartSample < audioSamples:
+			policy, err := r.handle(CheckError{Err: ErrAudioOverlap, Check: CheckAudioContinuity, Identifier: "MXJVAF64", Offset: afte.AudioFrameChunkOffset, Frame: frame, Expected: audioSamples, Actual: afte.StartSample}, "there is an overlap of %d samples at audio frame %d", audioSamples-afte.StartSample, frame)
+			if err != nil {
+				return nil, err
+			}
+			if policy == PolicyRepair {
+				trim := audioSamples - afte.StartSample
+				if trim >= uint64(afte.Samples) {
+					// The frame is completely covered by previous frames.
+					continue
+				}
+				if r.audioFrameTrims == nil {
+					r.audioFrameTrims = map[int]uint32{}
+				}
+				r.audioFrameTrims[len(result)] = uint32(trim)
+				afte.StartSample += trim
+				afte.Samples -= uint32(trim)
+			}
+		}
+		result = append(result, afte)
+		audioSamples = max(audioSamples, afte.StartSample+uint64(afte.Samples))
+	}
+
+	return result, nil
+}
+
+// silence is an io.Reader that returns an endless stream of the given byte value.
+type silence byte
+
+func (s silence) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(s)
+	}
+	return len(p), nil
+}
+
+// silenceValue returns the byte value that represents silence in the audio format of the file.
+func (r *Reader) silenceValue() silence {
+	// 8-bit PCM is unsigned, everything else is signed or floating point.
+	if r.Info.AudioFormat == mxriff64.AudioFormatPCM && r.Info.AudioChannelBitDepth <= 8 {
+		return 0x80
+	}
+	return 0
+}
@@ -10,38 +10,116 @@ import (
 	"fmt"
 	"io"
 	"iter"
+	"math"
 	"slices"
+	"sort"
+	"sync"
 
 	"github.com/Dadido3/mxv-demuxer/mxriff64"
 	go_cmp "github.com/google/go-cmp/cmp"
 )
 
+// Reader reads video and audio frames from a MXV file.
+//
+// Info and the lookup tables are filled by NewReader, and don't change afterwards.
+// Readers created with NewReaderAt are safe for concurrent use, and the readers returned by VideoFrameData and AudioFrameData stay valid until they are read completely.
+// Readers created with NewReader share a single file offset:
+// While they can be used from several goroutines, a returned frame data reader becomes invalid as soon as any other frame is requested.
 type Reader struct {
 	accessor *mxriff64.Accessor
+	options  ReaderOptions
+
+	mu sync.Mutex // Guards the shared accessor.
+
+	warningsMu sync.Mutex
+	warnings   Report // Problems that were ignored or repaired.
 
 	chunkVideoHeader2 *mxriff64.Chunk64MXJVH264
 	chunkVideoHeader  *mxriff64.Chunk64MXJVHD64
 	chunkWaveFormat   *mxriff64.Chunk64MXWFMT64
+	chunkCO64         *mxriff64.Chunk64MXJVCO64 // Unknown meaning, may be nil.
+	chunkPD64         *mxriff64.Chunk64MXJVPD64 // Unknown meaning, may be nil.
 	chunkFrameList    *mxriff64.Chunk64MXLIST64
 	chunkFrameTable   *mxriff64.Chunk64MXJVFT64
 	chunkLookupList   *mxriff64.Chunk64MXLIST32
 
-	// Info is filled by NewReader.
+	chunks []ChunkInfo // All top-level chunks in file order.
+
+	// Info is filled by NewReader, and must not be modified afterwards.
 	Info Info
 
+	// The cached lookup tables are filled by NewReader, and are not modified afterwards.
+	// If they couldn't be read or checked, the error is stored in lookupTableErr.
+	lookupTableErr error
+
 	// Cached list of video frame chunk offsets.
 	videoFrameOffsets []mxriff64.Chunk32VFTEData
 
 	// Cached list of audio frame chunk offsets.
 	audioFrameOffsets []mxriff64.Chunk32AFTEData
+
+	// Number of samples to skip at the start of repaired audio frames, by their frame number.
+	audioFrameTrims map[int]uint32
+}
+
+// ReaderOptions contains optional settings for NewReaderWithOptions.
+type ReaderOptions struct {
+	// Recover rebuilds the video and audio frame index by scanning the MXJVFL64 frame list, instead of using the MXJVTL32 and MXJVFT64 lookup tables.
+	// This is meant for files of crashed captures, where the lookup tables and header counts are missing or wrong.
+	//
+	// The scan stops cleanly at a truncated or damaged tail, and the frame and sample counts in Info are replaced by the recovered values.
+	Recover bool
+
+	// Policies defines how problems found by the checks of the reader are handled.
+	// Checks that are not in the map use PolicyFail.
+	//
+	// The reader uses the following checks:
+	//   - CheckHeaderMismatch: The extended video header MXJVH264 is used when the headers contradict each other.
+	//   - CheckVideoFrameCount, CheckAudioFrameCount, CheckAudioSampleCount: Repairing trusts the lookup list over the header counts, and updates Info.
+	//   - CheckAudioContinuity: Repairing pads gaps with silence and trims overlaps. In recovery mode, this check is only used with PolicyRepair.
+	//   - CheckChunkSize: The actual chunk size is used, no matter what the lookup entry says.
+	//   - CheckWaveFormat: The audio data is ignored, and Info.HasAudio is false.
+	//   - CheckLookupList: Only for MXJVHD64 files, where the audio counts are reconstructed from the lookup list. The audio frames that could be read are counted.
+	//
+	// If the MXJVTL32 lookup list is missing or damaged, the MXJVFT64 frame table is always used as video frame index, and a CheckLookupList warning is recorded.
+	//
+	// All ignored or repaired problems can be queried with Reader.Warnings.
+	Policies map[Check]Policy
 }
 
 // NewReader creates a new reader from the given io.ReadSeeker.
 func NewReader(rs io.ReadSeeker) (*Reader, error) {
+	return NewReaderWithOptions(rs, ReaderOptions{})
+}
+
+// NewReaderWithOptions creates a new reader from the given io.ReadSeeker, and with the given options.
+func NewReaderWithOptions(rs io.ReadSeeker, options ReaderOptions) (*Reader, error) {
+	return newReader(mxriff64.NewFromReadSeeker(rs), options)
+}
+
+// NewReaderAt creates a new reader from the given io.ReaderAt with the given size.
+//
+// The resulting reader only uses positional reads to access frame data, which makes it safe for concurrent use.
+func NewReaderAt(ra io.ReaderAt, size int64) (*Reader, error) {
+	return NewReaderAtWithOptions(ra, size, ReaderOptions{})
+}
+
+// NewReaderAtWithOptions creates a new reader from the given io.ReaderAt with the given size, and with the given options.
+//
+// The resulting reader only uses positional reads to access frame data, which makes it safe for concurrent use.
+func NewReaderAtWithOptions(ra io.ReaderAt, size int64, options ReaderOptions) (*Reader, error) {
+	return newReader(mxriff64.NewFromReaderAt(ra, size), options)
+}
+
+func newReader(a *mxriff64.Accessor, options ReaderOptions) (*Reader, error) {
 	r := &Reader{
-		accessor: mxriff64.NewFromReadSeeker(rs),
+		accessor: a,
+		options:  options,
 	}
 
+	// The root chunk and the frame list of truncated files extend beyond the end of the file.
+	a.AllowTruncated = options.Recover
+
 	rootChunk, err := r.accessor.ReadChunk64()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read root chunk: %w", err)
@@ -58,9 +136,15 @@ func NewReader(rs io.ReadSeeker) (*Reader, error) {
 
 	for sc, err := range mxriffChunk.Chunks() {
 		if err != nil {
+			if options.Recover {
+				// The tail of the file is truncated or damaged, continue with what we have got so far.
+				break
+			}
 			return nil, fmt.Errorf("failed to get sub-chunk from root chunk: %w", err)
 		}
 
+		r.chunks = append(r.chunks, ChunkInfo{Identifier: sc.Identifier(), Offset: sc.Offset(), Length: sc.Length(), Chunk: sc})
+
 		switch sc := sc.(type) {
 		case *mxriff64.Chunk64MXJVH264:
 			r.chunkVideoHeader2 = sc
@@ -68,6 +152,10 @@ func NewReader(rs io.ReadSeeker) (*Reader, error) {
 			r.chunkVideoHeader = sc
 		case *mxriff64.Chunk64MXWFMT64:
 			r.chunkWaveFormat = sc
+		case *mxriff64.Chunk64MXJVCO64:
+			r.chunkCO64 = sc
+		case *mxriff64.Chunk64MXJVPD64:
+			r.chunkPD64 = sc
 		case *mxriff64.Chunk64MXLIST64:
 			switch sc.Header.ContentType {
 			case mxriff64.ContentTypeMXJVFL64:
@@ -85,12 +173,16 @@ func NewReader(rs io.ReadSeeker) (*Reader, error) {
 
 	if r.chunkVideoHeader != nil && r.chunkVideoHeader2 != nil {
 		if r.chunkVideoHeader.Data != r.chunkVideoHeader2.Data.Chunk64MXJVHD64Data {
-			return nil, fmt.Errorf("the two video headers contain contradicting information:\n%s", go_cmp.Diff(r.chunkVideoHeader.Data, r.chunkVideoHeader2.Data.Chunk64MXJVHD64Data))
+			if _, err := r.handle(CheckError{Err: ErrHeaderMismatch, Check: CheckHeaderMismatch, Identifier: "MXJVHD64", Offset: r.chunkVideoHeader.Offset(), Frame: -1, Expected: r.chunkVideoHeader2.Data.Chunk64MXJVHD64Data, Actual: r.chunkVideoHeader.Data}, "the two video headers contain contradicting information:\n%s", go_cmp.Diff(r.chunkVideoHeader.Data, r.chunkVideoHeader2.Data.Chunk64MXJVHD64Data)); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	// TODO: Fall back to MXJVHD64 if there is no MXJVH264 chunk
-	if r.chunkVideoHeader2 != nil {
+	// Prefer the extended video header, and fall back to MXJVHD64 for older files.
+	switch {
+	case r.chunkVideoHeader2 != nil:
+		r.Info.VideoHeader = r.chunkVideoHeader2.Identifier()
 		r.Info.FrameWidth = r.chunkVideoHeader2.Data.FrameWidth   // Ignore FrameWidth2
 		r.Info.FrameHeight = r.chunkVideoHeader2.Data.FrameHeight // Ignore FrameHeight2
 		r.Info.Framerate = r.chunkVideoHeader2.Data.Framerate
@@ -98,11 +190,30 @@ func NewReader(rs io.ReadSeeker) (*Reader, error) {
 		r.Info.AspectRatio = r.chunkVideoHeader2.Data.AspectRatio
 		r.Info.ColorFormat = r.chunkVideoHeader2.Data.ColorFormat
 
-		r.Info.HasAudio = r.chunkVideoHeader2.Data.Flags&0b00000100 != 0
+		r.Info.Flags = r.chunkVideoHeader2.Data.Flags
 		r.Info.AudioFrames = r.chunkVideoHeader2.Data.AudioFrames
 		r.Info.AudioSamples = r.chunkVideoHeader2.Data.AudioSamples
-	} else {
-		return nil, fmt.Errorf("couldn't find a MXJVH264 chunk")
+	case r.chunkVideoHeader != nil:
+		r.Info.VideoHeader = r.chunkVideoHeader.Identifier()
+		r.Info.FrameWidth = r.chunkVideoHeader.Data.FrameWidth   // Ignore FrameWidth2
+		r.Info.FrameHeight = r.chunkVideoHeader.Data.FrameHeight // Ignore FrameHeight2
+		r.Info.Framerate = r.chunkVideoHeader.Data.Framerate
+		r.Info.VideoFrames = r.chunkVideoHeader.Data.VideoFrames
+
+		// MXJVHD64 contains neither the aspect ratio nor the color format, so assume square pixels and leave the color format unknown.
+		if r.Info.FrameHeight != 0 {
+			r.Info.AspectRatio = float64(r.Info.FrameWidth) / float64(r.Info.FrameHeight)
+		}
+
+		r.Info.Flags = r.chunkVideoHeader.Data.Flags
+	default:
+		return nil, fmt.Errorf("%w: MXJVH264 or MXJVHD64", ErrMissingChunk)
+	}
+
+	r.Info.HasAudio = r.Info.Flags.HasAudio()
+	r.Info.Interlaced = r.Info.Flags.Interlaced()
+	if r.Info.Interlaced {
+		r.Info.FieldOrder = r.Info.Flags.FieldOrder()
 	}
 
 	if r.Info.HasAudio {
@@ -114,10 +225,49 @@ func NewReader(rs io.ReadSeeker) (*Reader, error) {
 			r.Info.AudioBytesPerSample = r.chunkWaveFormat.Data.BytesPerSample
 			r.Info.AudioChannelBitDepth = r.chunkWaveFormat.Data.ChannelBitDepth
 		} else {
-			return nil, fmt.Errorf("couldn't find MXWFMT64 chunk even though container should have audio data")
+			if _, err := r.handle(CheckError{Err: ErrMissingChunk, Check: CheckWaveFormat, Identifier: "MXWFMT64", Offset: -1, Frame: -1}, "%v: MXWFMT64, even though container should have audio data", ErrMissingChunk); err != nil {
+				return nil, err
+			}
+			// Without the audio format, the audio data can't be interpreted.
+			r.Info.HasAudio = false
+		}
+	}
+
+	if r.Info.HasAudio {
+		// MXJVHD64 doesn't contain any audio frame or sample counts, reconstruct them from the lookup list.
+		if r.chunkVideoHeader2 == nil && !options.Recover {
+			_, audioFrameOffsets, err := r.readLookupTable()
+			if err != nil {
+				offset := int64(-1)
+				if r.chunkLookupList != nil {
+					offset = r.chunkLookupList.Offset()
+				}
+				if _, err := r.handle(CheckError{Err: ErrLookupList, Check: CheckLookupList, Identifier: "MXLIST32", Offset: offset, Frame: -1}, "failed to reconstruct audio information from lookup table: %v", err); err != nil {
+					return nil, err
+				}
+				// Continue with the audio frames that could be read.
+			}
+			r.Info.AudioFrames = uint64(len(audioFrameOffsets))
+			for _, afte := range audioFrameOffsets {
+				r.Info.AudioSamples += uint64(afte.Samples)
+			}
+		}
+	}
+
+	// Read and check the lookup table right away, as this may update the counts in Info.
+	// This way Info doesn't change once the reader is handed out, and can be read without locking.
+	// Errors are not returned here, so that the headers can still be inspected, see PrepareLookupTable.
+	if options.Recover {
+		if err := r.recoverLookupTable(); err != nil {
+			return nil, fmt.Errorf("failed to recover frame chunk lookup table: %w", err)
 		}
+	} else {
+		r.lookupTableErr = r.prepareLookupTable()
 	}
 
+	// The counts may have been repaired above, so the exact framerate is derived afterwards.
+	r.Info.FramerateNum, r.Info.FramerateDen = exactFramerate(r.Info.Framerate, r.Info.VideoFrames, r.Info.AudioSamples, r.Info.AudioSampleRate)
+
 	return r, nil
 }
 
@@ -125,12 +275,12 @@ func NewReader(rs io.ReadSeeker) (*Reader, error) {
 //
 // The frame data can be read by calling VideoFrameData with the frame number returned by this iterator.
 //
-// To ensure this function succeeds, you have to call `PrepareLookupTable()` first.
+// If the lookup table couldn't be read completely, this only returns the frames that could be read, see PrepareLookupTable.
 func (r *Reader) VideoFrames() iter.Seq2[int, mxriff64.Chunk32VFTEData] {
 	return func(yield func(int, mxriff64.Chunk32VFTEData) bool) {
-		r.PrepareLookupTable() // Ignore error.
+		videoFrameOffsets := r.videoFrameOffsets
 
-		for frame, vfte := range r.videoFrameOffsets {
+		for frame, vfte := range videoFrameOffsets {
 			if !yield(frame, vfte) {
 				return
 			}
@@ -146,32 +296,35 @@ func (r *Reader) VideoFrameData(frame int) (io.Reader, error) {
 		return nil, fmt.Errorf("failed to prepare frame chunk lookup table: %w", err)
 	}
 
-	if r.videoFrameOffsets == nil {
+	videoFrameOffsets := r.videoFrameOffsets
+
+	if videoFrameOffsets == nil {
 		return nil, fmt.Errorf("container doesn't contain any video frame chunk lookup entries")
 	}
 
-	if frame < 0 || frame >= len(r.videoFrameOffsets) {
-		return nil, fmt.Errorf("requested video frame %d is outside of the valid range from %d to %d", frame, 0, len(r.videoFrameOffsets)-1)
+	if frame < 0 || frame >= len(videoFrameOffsets) {
+		return nil, frameRangeError("MXJVVF64", frame, len(videoFrameOffsets))
 	}
-	vfte := r.videoFrameOffsets[frame]
+	vfte := videoFrameOffsets[frame]
 
-	if _, err := r.accessor.Seek(vfte.VideoFrameChunkOffset, io.SeekStart); err != nil {
-		return nil, fmt.Errorf("failed to seek to video frame chunk: %w", err)
-	}
+	unlock := r.lockAccessor()
+	defer unlock()
 
-	chunk, err := r.accessor.ReadChunk64()
+	chunk, err := r.accessor.ReadChunk64At(vfte.VideoFrameChunkOffset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read chunk: %w", err)
+		return nil, &FrameError{Identifier: "MXJVVF64", Frame: frame, Offset: vfte.VideoFrameChunkOffset, Err: fmt.Errorf("failed to read chunk: %w", err)}
 	}
 
 	// Check size, but only if the VideoFrameChunkSize field is != 0.
 	// VideoFrameChunkSize being zero may be some sort of corruption that occurs in older MXV files.
 	if vfte.VideoFrameChunkSize != 0 && chunk.Length() != int64(vfte.VideoFrameChunkSize) {
-		return nil, fmt.Errorf("parsed chunk is of wrong size. Got %d bytes, want %d bytes", chunk.Length(), vfte.VideoFrameChunkSize)
+		if _, err := r.handle(CheckError{Err: ErrChunkSize, Check: CheckChunkSize, Identifier: "MXJVVF64", Offset: vfte.VideoFrameChunkOffset, Frame: frame, Expected: int64(vfte.VideoFrameChunkSize), Actual: chunk.Length()}, "parsed chunk is of wrong size. Got %d bytes, want %d bytes", chunk.Length(), vfte.VideoFrameChunkSize); err != nil {
+			return nil, err
+		}
 	}
 
 	if frameChunk, ok := chunk.(*mxriff64.Chunk64MXJVVF64); !ok {
-		return nil, fmt.Errorf("parsed chunk is not a video frame chunk. Got %T, want %T", chunk, frameChunk)
+		return nil, &FrameError{Identifier: "MXJVVF64", Frame: frame, Offset: vfte.VideoFrameChunkOffset, Err: fmt.Errorf("%w. Got %T, want %T", ErrFrameChunk, chunk, frameChunk)}
 	} else {
 		return frameChunk.DataReader()
 	}
@@ -181,12 +334,12 @@ func (r *Reader) VideoFrameData(frame int) (io.Reader, error) {
 //
 // The frame data can be read by calling AudioFrameData with the frame number returned by this iterator.
 //
-// To ensure this function succeeds, you have to call `PrepareLookupTable()` first.
+// If the lookup table couldn't be read completely, this only returns the frames that could be read, see PrepareLookupTable.
 func (r *Reader) AudioFrames() iter.Seq2[int, mxriff64.Chunk32AFTEData] {
 	return func(yield func(int, mxriff64.Chunk32AFTEData) bool) {
-		r.PrepareLookupTable() // Ignore error.
+		audioFrameOffsets := r.audioFrameOffsets
 
-		for frame, afte := range r.audioFrameOffsets {
+		for frame, afte := range audioFrameOffsets {
 			if !yield(frame, afte) {
 				return
 			}
@@ -195,17 +348,23 @@ func (r *Reader) AudioFrames() iter.Seq2[int, mxriff64.Chunk32AFTEData] {
 }
 
 // AudioFrameFromSample returns the frame number and sample length of the audio frame that contains the given sample.
+//
+// This uses a binary search over the start samples of all audio frames.
 func (r *Reader) AudioFrameFromSample(sample uint64) (frame int, samples uint32, err error) {
 	if err := r.PrepareLookupTable(); err != nil {
 		return 0, 0, fmt.Errorf("failed to prepare frame chunk lookup table: %w", err)
 	}
 
-	if r.audioFrameOffsets == nil {
+	audioFrameOffsets := r.audioFrameOffsets
+
+	if audioFrameOffsets == nil {
 		return 0, 0, fmt.Errorf("container doesn't contain any audio frame chunk lookup entries")
 	}
 
-	for frame, afte := range r.audioFrameOffsets {
-		if sample >= afte.StartSample && sample < afte.StartSample+uint64(afte.Samples) {
+	// The audio frames are sorted by their start sample, so the frame that contains the sample is the last one that starts at or before it.
+	frame = sort.Search(len(audioFrameOffsets), func(i int) bool { return audioFrameOffsets[i].StartSample > sample }) - 1
+	if frame >= 0 {
+		if afte := audioFrameOffsets[frame]; sample < afte.StartSample+uint64(afte.Samples) {
 			return frame, afte.Samples, nil
 		}
 	}
@@ -222,87 +381,350 @@ func (r *Reader) AudioFrameData(frame int) (reader io.Reader, startSample uint64
 		return nil, 0, 0, fmt.Errorf("failed to prepare frame chunk lookup table: %w", err)
 	}
 
-	if r.audioFrameOffsets == nil {
+	audioFrameOffsets := r.audioFrameOffsets
+	trim, trimmed := r.audioFrameTrims[frame]
+
+	if audioFrameOffsets == nil {
 		return nil, 0, 0, fmt.Errorf("container doesn't contain any audio frame chunk lookup entries")
 	}
 
-	if frame < 0 || frame >= len(r.audioFrameOffsets) {
-		return nil, 0, 0, fmt.Errorf("requested audio frame %d is outside of the valid range from %d to %d", frame, 0, len(r.audioFrameOffsets)-1)
+	if frame < 0 || frame >= len(audioFrameOffsets) {
+		return nil, 0, 0, frameRangeError("MXJVAF64", frame, len(audioFrameOffsets))
 	}
-	afte := r.audioFrameOffsets[frame]
+	afte := audioFrameOffsets[frame]
 
-	if _, err := r.accessor.Seek(afte.AudioFrameChunkOffset, io.SeekStart); err != nil {
-		return nil, 0, 0, fmt.Errorf("failed to seek to audio frame chunk: %w", err)
+	// Silence that was inserted to fill a gap.
+	if afte.AudioFrameChunkOffset < 0 {
+		return io.LimitReader(r.silenceValue(), int64(afte.Samples)*int64(r.Info.AudioBytesPerSample)), afte.StartSample, afte.Samples, nil
 	}
 
-	chunk, err := r.accessor.ReadChunk64()
+	unlock := r.lockAccessor()
+	defer unlock()
+
+	chunk, err := r.accessor.ReadChunk64At(afte.AudioFrameChunkOffset)
 	if err != nil {
-		return nil, 0, 0, fmt.Errorf("failed to read chunk: %w", err)
+		return nil, 0, 0, &FrameError{Identifier: "MXJVAF64", Frame: frame, Offset: afte.AudioFrameChunkOffset, Err: fmt.Errorf("failed to read chunk: %w", err)}
 	}
 
 	// Check size, but only if the AudioFrameChunkSize field is != 0.
 	// AudioFrameChunkSize being zero may be some sort of corruption that occurs in older MXV files.
 	if afte.AudioFrameChunkSize != 0 && chunk.Length() != int64(afte.AudioFrameChunkSize) {
-		return nil, 0, 0, fmt.Errorf("parsed chunk is of wrong size. Got %d bytes, want %d bytes", chunk.Length(), afte.AudioFrameChunkSize)
+		if _, err := r.handle(CheckError{Err: ErrChunkSize, Check: CheckChunkSize, Identifier: "MXJVAF64", Offset: afte.AudioFrameChunkOffset, Frame: frame, Expected: int64(afte.AudioFrameChunkSize), Actual: chunk.Length()}, "parsed chunk is of wrong size. Got %d bytes, want %d bytes", chunk.Length(), afte.AudioFrameChunkSize); err != nil {
+			return nil, 0, 0, err
+		}
 	}
 
-	if frameChunk, ok := chunk.(*mxriff64.Chunk64MXJVAF64); !ok {
-		return nil, 0, 0, fmt.Errorf("parsed chunk is not an audio frame chunk. Got %T, want %T", chunk, frameChunk)
-	} else {
-		reader, err := frameChunk.DataReader()
-		return reader, frameChunk.Data.StartSample, frameChunk.Data.Samples, err
+	frameChunk, ok := chunk.(*mxriff64.Chunk64MXJVAF64)
+	if !ok {
+		return nil, 0, 0, &FrameError{Identifier: "MXJVAF64", Frame: frame, Offset: afte.AudioFrameChunkOffset, Err: fmt.Errorf("%w. Got %T, want %T", ErrFrameChunk, chunk, frameChunk)}
+	}
+
+	reader, err = frameChunk.DataReader()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	// Skip the samples that were trimmed to remove an overlap.
+	if trimmed {
+		if _, err := io.CopyN(io.Discard, reader, int64(trim)*int64(r.Info.AudioBytesPerSample)); err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to skip %d overlapping samples: %w", trim, err)
+		}
+		return io.LimitReader(reader, int64(afte.Samples)*int64(r.Info.AudioBytesPerSample)), afte.StartSample, afte.Samples, nil
+	}
+
+	return reader, frameChunk.Data.StartSample, frameChunk.Data.Samples, nil
+}
+
+// lockAccessor locks the shared accessor if it is needed to read frame chunks, and returns the function to unlock it again.
+// In io.ReaderAt mode, every frame chunk is read independently, so there is nothing to lock.
+func (r *Reader) lockAccessor() (unlock func()) {
+	if r.accessor.ReaderAt != nil {
+		return func() {}
 	}
+
+	r.mu.Lock()
+	return r.mu.Unlock
 }
 
-// Reads and caches the audio and video frame chunk lookup table.
+// PrepareLookupTable returns the error that occurred while reading and checking the audio and video frame chunk lookup table, if any.
 //
+// The lookup table is already read and cached by NewReader, so calling this is only needed to check for errors.
+// The same error is also returned by all functions that access frame data.
 // Keeping this table in RAM uses about 12 bytes per video and 24 bytes per audio frame.
 func (r *Reader) PrepareLookupTable() error {
-	if r.videoFrameOffsets != nil || r.audioFrameOffsets != nil {
-		return nil
+	return r.lookupTableErr
+}
+
+// prepareLookupTable reads and checks the audio and video frame chunk lookup table, and caches it.
+// The counts in Info are updated according to the policies.
+//
+// This is only called by newReader, before the reader is handed out.
+func (r *Reader) prepareLookupTable() error {
+	videoFrameOffsets, audioFrameOffsets, err := r.readLookupTable()
+	if err == nil && r.Info.VideoFrames != uint64(len(videoFrameOffsets)) && r.options.policy(CheckVideoFrameCount) == PolicyFail {
+		err = &CheckError{Err: ErrVideoFrameCount, Check: CheckVideoFrameCount, Offset: -1, Frame: -1, Expected: r.Info.VideoFrames, Actual: uint64(len(videoFrameOffsets)),
+			Message: fmt.Sprintf("actual number of video frames (%d) differs from header value (%d)", len(videoFrameOffsets), r.Info.VideoFrames)}
+	}
+	if err != nil {
+		// The lookup list is missing or damaged, try to use the frame table as video frame index.
+		// The audio frames that could be read from the lookup list are still used.
+		var ftErr error
+		if videoFrameOffsets, ftErr = r.readFrameTable(); ftErr != nil {
+			return fmt.Errorf("failed to read lookup list: %w. Also failed to read the frame table as alternative: %w", err, ftErr)
+		}
+
+		// The frame table is always used as alternative, so the problem with the lookup list is only recorded as warning.
+		offset := int64(-1)
+		if r.chunkLookupList != nil {
+			offset = r.chunkLookupList.Offset()
+		}
+		r.warn(CheckError{Err: ErrLookupList, Check: CheckLookupList, Identifier: "MXLIST32", Offset: offset, Frame: -1,
+			Message: fmt.Sprintf("failed to use lookup list, the MXJVFT64 frame table is used as video frame index instead: %v", err)})
 	}
+	r.videoFrameOffsets, r.audioFrameOffsets = videoFrameOffsets, audioFrameOffsets
 
-	// Cache is empty, rebuild it.
+	// Check that we got as many frames as stated in the header.
+	if r.Info.VideoFrames != uint64(len(r.videoFrameOffsets)) {
+		policy, err := r.handle(CheckError{Err: ErrVideoFrameCount, Check: CheckVideoFrameCount, Offset: -1, Frame: -1, Expected: r.Info.VideoFrames, Actual: uint64(len(r.videoFrameOffsets))}, "actual number of video frames (%d) differs from header value (%d)", len(r.videoFrameOffsets), r.Info.VideoFrames)
+		if err != nil {
+			return err
+		}
+		if policy == PolicyRepair {
+			r.Info.VideoFrames = uint64(len(r.videoFrameOffsets))
+		}
+	}
+	if r.Info.AudioFrames != uint64(len(r.audioFrameOffsets)) {
+		policy, err := r.handle(CheckError{Err: ErrAudioFrameCount, Check: CheckAudioFrameCount, Offset: -1, Frame: -1, Expected: r.Info.AudioFrames, Actual: uint64(len(r.audioFrameOffsets))}, "actual number of audio frames (%d) differs from header value (%d)", len(r.audioFrameOffsets), r.Info.AudioFrames)
+		if err != nil {
+			return err
+		}
+		if policy == PolicyRepair {
+			r.Info.AudioFrames = uint64(len(r.audioFrameOffsets))
+		}
+	}
 
+	// Also check that we got the promised amount of audio samples without gaps or overlaps.
+	repaired, err := r.handleAudioContinuity(r.audioFrameOffsets)
+	if err != nil {
+		return err
+	}
+	if len(repaired) != len(r.audioFrameOffsets) && r.Info.AudioFrames == uint64(len(r.audioFrameOffsets)) {
+		// Silence frames were inserted or covered frames were removed.
+		r.Info.AudioFrames = uint64(len(repaired))
+	}
+	r.audioFrameOffsets = repaired
+
+	var audioSamples uint64
+	for _, afte := range r.audioFrameOffsets {
+		audioSamples = max(audioSamples, afte.StartSample+uint64(afte.Samples))
+	}
+	if audioSamples != r.Info.AudioSamples {
+		policy, err := r.handle(CheckError{Err: ErrAudioSampleCount, Check: CheckAudioSampleCount, Offset: -1, Frame: -1, Expected: r.Info.AudioSamples, Actual: audioSamples}, "actual number of audio samples (%d) differs from header value (%d)", audioSamples, r.Info.AudioSamples)
+		if err != nil {
+			return err
+		}
+		if policy == PolicyRepair {
+			r.Info.AudioSamples = audioSamples
+		}
+	}
+
+	return nil
+}
+
+// readLookupTable reads all VFTE and AFTE entries from the MXJVTL32 lookup list.
+// The audio frames are sorted by their start sample.
+//
+// In case of an error, all entries that could be read until then are returned.
+func (r *Reader) readLookupTable() (videoFrameOffsets []mxriff64.Chunk32VFTEData, audioFrameOffsets []mxriff64.Chunk32AFTEData, err error) {
 	if r.chunkLookupList == nil {
-		return fmt.Errorf("couldn't find MXLIST32 chunk with %s", mxriff64.ContentTypeMXJVTL32)
+		return nil, nil, fmt.Errorf("%w: MXLIST32 with %s", ErrMissingChunk, mxriff64.ContentTypeMXJVTL32)
 	}
 
 	// Read frame table from container.
 	for chunk, err := range r.chunkLookupList.Chunks() {
 		if err != nil {
-			return fmt.Errorf("failed to get sub-chunk from audio/video lookup table: %w", err)
+			return videoFrameOffsets, audioFrameOffsets, fmt.Errorf("failed to get sub-chunk from audio/video lookup table: %w", err)
 		}
 		switch chunk := chunk.(type) {
 		case *mxriff64.Chunk32VFTE:
-			r.videoFrameOffsets = append(r.videoFrameOffsets, chunk.Data)
+			videoFrameOffsets = append(videoFrameOffsets, chunk.Data)
 		case *mxriff64.Chunk32AFTE:
-			r.audioFrameOffsets = append(r.audioFrameOffsets, chunk.Data)
+			audioFrameOffsets = append(audioFrameOffsets, chunk.Data)
 		}
 	}
 
 	// Ensure that the audio frames are ordered, even though they are most likely already in order.
-	slices.SortFunc(r.audioFrameOffsets, func(a, b mxriff64.Chunk32AFTEData) int { return cmp.Compare(a.StartSample, b.StartSample) })
+	slices.SortFunc(audioFrameOffsets, func(a, b mxriff64.Chunk32AFTEData) int { return cmp.Compare(a.StartSample, b.StartSample) })
 
-	// Check that we got as many frames as stated in the header.
-	if r.Info.VideoFrames != uint64(len(r.videoFrameOffsets)) {
-		return fmt.Errorf("actual number of video frames (%d) differs from header value (%d)", len(r.videoFrameOffsets), r.Info.VideoFrames)
+	return videoFrameOffsets, audioFrameOffsets, nil
+}
+
+// readFrameTable builds the video frame index from the MXJVFT64 offset table.
+//
+// The offset table doesn't contain the chunk sizes, so VideoFrameChunkSize is always 0.
+func (r *Reader) readFrameTable() ([]mxriff64.Chunk32VFTEData, error) {
+	if r.chunkFrameTable == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingChunk, (&mxriff64.Chunk64MXJVFT64{}).Identifier())
 	}
-	if r.Info.AudioFrames != uint64(len(r.audioFrameOffsets)) {
-		return fmt.Errorf("actual number of audio frames (%d) differs from header value (%d)", len(r.audioFrameOffsets), r.Info.AudioFrames)
+
+	frameOffsets, _, err := r.chunkFrameTable.Offsets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frame table: %w", err)
 	}
 
-	// Also check that we got the promised amount of audio samples without gaps or overlaps.
-	var audioSamples uint64
-	for _, afte := range r.audioFrameOffsets {
-		if afte.StartSample != audioSamples {
-			return fmt.Errorf("there is a gap or overlap in the audio data")
+	if r.Info.VideoFrames != uint64(len(frameOffsets)) {
+		return nil, &CheckError{Err: ErrVideoFrameCount, Check: CheckVideoFrameCount, Identifier: "MXJVFT64", Offset: r.chunkFrameTable.Offset(), Frame: -1, Expected: r.Info.VideoFrames, Actual: uint64(len(frameOffsets)),
+			Message: fmt.Sprintf("number of frame table entries (%d) differs from header value (%d)", len(frameOffsets), r.Info.VideoFrames)}
+	}
+
+	videoFrameOffsets := make([]mxriff64.Chunk32VFTEData, 0, len(frameOffsets))
+	for _, offset := range frameOffsets {
+		videoFrameOffsets = append(videoFrameOffsets, mxriff64.Chunk32VFTEData{VideoFrameChunkOffset: offset})
+	}
+
+	return videoFrameOffsets, nil
+}
+
+// FrameTableDisagreement describes a video frame whose entry in the MXJVFT64 offset table differs from its VFTE entry in the MXJVTL32 lookup list.
+type FrameTableDisagreement struct {
+	Frame            int   // The video frame number. For the terminating entry of the offset table, this is the number of video frames.
+	FrameTableOffset int64 // The offset from the MXJVFT64 chunk, or -1 if there is no entry for this frame.
+	LookupListOffset int64 // The expected offset. This is the VideoFrameChunkOffset of the VFTE entry, or the file offset of the MXJVFT64 chunk for the terminating entry. -1 if there is no VFTE entry for this frame.
+}
+
+func (d FrameTableDisagreement) String() string {
+	return fmt.Sprintf("video frame %d: frame table offset %d, lookup list offset %d", d.Frame, d.FrameTableOffset, d.LookupListOffset)
+}
+
+// CheckFrameTable compares the MXJVFT64 offset table with the VFTE entries of the MXJVTL32 lookup list, and returns all disagreements.
+// This also checks that the terminating entry of the offset table points to the MXJVFT64 chunk itself.
+//
+// An error is returned if any of the two tables can't be read.
+func (r *Reader) CheckFrameTable() ([]FrameTableDisagreement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.chunkFrameTable == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingChunk, (&mxriff64.Chunk64MXJVFT64{}).Identifier())
+	}
+
+	frameOffsets, terminator, err := r.chunkFrameTable.Offsets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frame table: %w", err)
+	}
+
+	vftes, _, err := r.readLookupTable()
+	if err != nil {
+		return nil, err
+	}
+
+	var disagreements []FrameTableDisagreement
+	for frame := range max(len(frameOffsets), len(vftes)) {
+		d := FrameTableDisagreement{Frame: frame, FrameTableOffset: -1, LookupListOffset: -1}
+		if frame < len(frameOffsets) {
+			d.FrameTableOffset = frameOffsets[frame]
+		}
+		if frame < len(vftes) {
+			d.LookupListOffset = vftes[frame].VideoFrameChunkOffset
+		}
+		if d.FrameTableOffset != d.LookupListOffset {
+			disagreements = append(disagreements, d)
 		}
-		audioSamples += uint64(afte.Samples)
 	}
-	if audioSamples != r.Info.AudioSamples {
-		return fmt.Errorf("actual number of audio samples (%d) differs from header value (%d)", audioSamples, r.Info.AudioSamples)
+
+	if chunkOffset := r.chunkFrameTable.Offset(); terminator != chunkOffset {
+		disagreements = append(disagreements, FrameTableDisagreement{Frame: len(frameOffsets), FrameTableOffset: terminator, LookupListOffset: chunkOffset})
+	}
+
+	return disagreements, nil
+}
+
+// recoverLookupTable rebuilds the cached video and audio frame chunk lookup table by scanning the MXJVFL64 frame list.
+// The frame and sample counts in Info are updated to match the recovered frames.
+//
+// The scan stops at the first chunk that can't be read or that goes beyond the end of the file.
+// Audio frames are placed by their start sample, there may be gaps between them.
+func (r *Reader) recoverLookupTable() error {
+	if r.chunkFrameList == nil {
+		return fmt.Errorf("%w: MXLIST64 with %s", ErrMissingChunk, mxriff64.ContentTypeMXJVFL64)
+	}
+
+	// Only seek to the end if the accessor doesn't know the file size, and restore the file offset afterwards.
+	fileSize := r.accessor.Size()
+	if fileSize <= 0 {
+		pos := r.accessor.Pos
+		size, err := r.accessor.Seek(0, io.SeekEnd)
+		if err != nil {
+			return fmt.Errorf("failed to determine file size: %w", err)
+		}
+		if _, err := r.accessor.Seek(pos, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to seek back to offset %d: %w", pos, err)
+		}
+		fileSize = size
+	}
+
+	var videoFrameOffsets []mxriff64.Chunk32VFTEData
+	var audioFrameOffsets []mxriff64.Chunk32AFTEData
+
+scan:
+	for chunk, err := range r.chunkFrameList.Chunks() {
+		if err != nil {
+			// The tail of the list is truncated or damaged.
+			break
+		}
+
+		// Chunk sizes that don't fit into the lookup entries are stored as 0, which disables the size check.
+		var chunkSize uint32
+		if chunk.Length() <= math.MaxUint32 {
+			chunkSize = uint32(chunk.Length())
+		}
+
+		switch chunk := chunk.(type) {
+		case *mxriff64.Chunk64MXJVVF64:
+			if chunk.Offset()+chunk.Length() > fileSize {
+				break scan
+			}
+			videoFrameOffsets = append(videoFrameOffsets, mxriff64.Chunk32VFTEData{
+				VideoFrameChunkOffset: chunk.Offset(),
+				VideoFrameChunkSize:   chunkSize,
+			})
+		case *mxriff64.Chunk64MXJVAF64:
+			if chunk.Offset()+chunk.Length() > fileSize {
+				break scan
+			}
+			audioFrameOffsets = append(audioFrameOffsets, mxriff64.Chunk32AFTEData{
+				AudioFrameChunkOffset: chunk.Offset(),
+				AudioFrameChunkSize:   chunkSize,
+				StartSample:           chunk.Data.StartSample,
+				Samples:               chunk.Data.Samples,
+			})
+		}
+	}
+
+	// This also ensures that PrepareLookupTable doesn't overwrite the recovered table, as the cache isn't empty.
+	if len(videoFrameOffsets) == 0 {
+		return fmt.Errorf("couldn't find any readable video frame")
+	}
+
+	// The audio frames may not be stored in order.
+	slices.SortStableFunc(audioFrameOffsets, func(a, b mxriff64.Chunk32AFTEData) int { return cmp.Compare(a.StartSample, b.StartSample) })
+
+	// Gaps are expected in recovered files, so they are only handled if they should be repaired.
+	if r.options.policy(CheckAudioContinuity) == PolicyRepair {
+		var err error
+		if audioFrameOffsets, err = r.handleAudioContinuity(audioFrameOffsets); err != nil {
+			return err
+		}
 	}
 
+	r.Info.VideoFrames = uint64(len(videoFrameOffsets))
+	r.Info.AudioFrames = uint64(len(audioFrameOffsets))
+	r.Info.AudioSamples = 0
+	for _, afte := range audioFrameOffsets {
+		r.Info.AudioSamples = max(r.Info.AudioSamples, afte.StartSample+uint64(afte.Samples))
+	}
+
+	r.videoFrameOffsets, r.audioFrameOffsets = videoFrameOffsets, audioFrameOffsets
+
 	return nil
 }
@@ -6,13 +6,27 @@
 package mxv_test
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/Dadido3/mxv-demuxer/mxriff64"
 	"github.com/Dadido3/mxv-demuxer/mxv"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+var (
+	identifierMXJVH264 = mxriff64.Identifier64{'M', 'X', 'J', 'V', 'H', '2', '6', '4'}
+	identifierMXJVHD64 = mxriff64.Identifier64{'M', 'X', 'J', 'V', 'H', 'D', '6', '4'}
 )
 
 func TestNewReader(t *testing.T) {
@@ -23,7 +37,8 @@ func TestNewReader(t *testing.T) {
 		{
 			filepath: filepath.Join("..", "example-files", "Vergleich2.mxv"),
 			mxvInfo: mxv.Info{
-				ColorFormat: mxriff64.ColorFormatYUY2, FrameWidth: 720, FrameHeight: 576, Framerate: 25, VideoFrames: 349, AspectRatio: 1.3333332999999998,
+				VideoHeader: identifierMXJVH264,
+				ColorFormat: mxriff64.ColorFormatYUY2, FrameWidth: 720, FrameHeight: 576, Framerate: 25, FramerateNum: 25, FramerateDen: 1, VideoFrames: 349, AspectRatio: 1.3333332999999998, Flags: mxriff64.VideoFlagHasAudio,
 				HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioByteRate: 192000,
 				AudioBytesPerSample: 4, AudioChannelBitDepth: 16, AudioFrames: 28, AudioSamples: 672000,
 			},
@@ -31,7 +46,8 @@ func TestNewReader(t *testing.T) {
 		{
 			filepath: filepath.Join("..", "example-files", "23.976p.mxv"),
 			mxvInfo: mxv.Info{
-				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1920, FrameHeight: 1080, Framerate: 23.976, VideoFrames: 48, AspectRatio: 1.7777777777777777,
+				VideoHeader: identifierMXJVH264,
+				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1920, FrameHeight: 1080, Framerate: 23.976, FramerateNum: 24000, FramerateDen: 1001, VideoFrames: 48, AspectRatio: 1.7777777777777777, Flags: mxriff64.VideoFlagHasAudio,
 				HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioByteRate: 192000,
 				AudioBytesPerSample: 4, AudioChannelBitDepth: 16, AudioFrames: 48, AudioSamples: 96096,
 			},
@@ -39,7 +55,8 @@ func TestNewReader(t *testing.T) {
 		{
 			filepath: filepath.Join("..", "example-files", "24p.mxv"),
 			mxvInfo: mxv.Info{
-				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1920, FrameHeight: 1080, Framerate: 24, VideoFrames: 48, AspectRatio: 1.7777777777777777,
+				VideoHeader: identifierMXJVH264,
+				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1920, FrameHeight: 1080, Framerate: 24, FramerateNum: 24, FramerateDen: 1, VideoFrames: 48, AspectRatio: 1.7777777777777777, Flags: mxriff64.VideoFlagHasAudio,
 				HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioByteRate: 192000,
 				AudioBytesPerSample: 4, AudioChannelBitDepth: 16, AudioFrames: 48, AudioSamples: 96000,
 			},
@@ -47,7 +64,8 @@ func TestNewReader(t *testing.T) {
 		{
 			filepath: filepath.Join("..", "example-files", "25i.mxv"),
 			mxvInfo: mxv.Info{
-				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1440, FrameHeight: 1080, Framerate: 25, VideoFrames: 50, AspectRatio: 1.7777777777777777,
+				VideoHeader: identifierMXJVH264,
+				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1440, FrameHeight: 1080, Framerate: 25, FramerateNum: 25, FramerateDen: 1, VideoFrames: 50, AspectRatio: 1.7777777777777777, Flags: mxriff64.VideoFlagHasAudio,
 				HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioByteRate: 192000,
 				AudioBytesPerSample: 4, AudioChannelBitDepth: 16, AudioFrames: 50, AudioSamples: 96000,
 			},
@@ -55,7 +73,8 @@ func TestNewReader(t *testing.T) {
 		{
 			filepath: filepath.Join("..", "example-files", "50p.mxv"),
 			mxvInfo: mxv.Info{
-				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1920, FrameHeight: 1080, Framerate: 50, VideoFrames: 100, AspectRatio: 1.7777777777777777,
+				VideoHeader: identifierMXJVH264,
+				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1920, FrameHeight: 1080, Framerate: 50, FramerateNum: 50, FramerateDen: 1, VideoFrames: 100, AspectRatio: 1.7777777777777777, Flags: mxriff64.VideoFlagHasAudio,
 				HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioByteRate: 192000,
 				AudioBytesPerSample: 4, AudioChannelBitDepth: 16, AudioFrames: 100, AudioSamples: 96000,
 			},
@@ -63,7 +82,8 @@ func TestNewReader(t *testing.T) {
 		{
 			filepath: filepath.Join("..", "example-files", "60p.mxv"),
 			mxvInfo: mxv.Info{
-				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1920, FrameHeight: 1080, Framerate: 60, VideoFrames: 120, AspectRatio: 1.7777777777777777,
+				VideoHeader: identifierMXJVH264,
+				ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 1920, FrameHeight: 1080, Framerate: 60, FramerateNum: 60, FramerateDen: 1, VideoFrames: 120, AspectRatio: 1.7777777777777777, Flags: mxriff64.VideoFlagHasAudio,
 				HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioByteRate: 192000,
 				AudioBytesPerSample: 4, AudioChannelBitDepth: 16, AudioFrames: 120, AudioSamples: 96000,
 			},
@@ -89,3 +109,826 @@ func TestNewReader(t *testing.T) {
 		})
 	}
 }
+
+// TestNewReaderFallback checks that files without MXJVH264 chunk are read by using the MXJVHD64 chunk.
+func TestNewReaderFallback(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	// Rename the MXJVH264 chunk, so that it is parsed as unknown chunk.
+	i := bytes.Index(data, identifierMXJVH264[:])
+	if i < 0 {
+		t.Fatalf("Couldn't find MXJVH264 chunk.")
+	}
+	copy(data[i:], "MXJVXXXX")
+
+	mxvReader, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	wantInfo := mxv.Info{
+		VideoHeader: identifierMXJVHD64,
+		FrameWidth:  1920, FrameHeight: 1080, Framerate: 25, FramerateNum: 25, FramerateDen: 1, VideoFrames: 50, AspectRatio: 1.7777777777777777, Flags: mxriff64.VideoFlagHasAudio,
+		HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioByteRate: 192000,
+		AudioBytesPerSample: 4, AudioChannelBitDepth: 16, AudioFrames: 50, AudioSamples: 96000,
+	}
+	if !cmp.Equal(wantInfo, mxvReader.Info) {
+		t.Errorf("Parsed video info differs from expected result:\n%s", cmp.Diff(wantInfo, mxvReader.Info))
+	}
+
+	if err := mxvReader.PrepareLookupTable(); err != nil {
+		t.Errorf("Failed to prepare lookup table: %v.", err)
+	}
+}
+
+// TestPrepareLookupTableFrameTable checks that the MXJVFT64 offset table is used as video frame index if the lookup list is damaged.
+func TestPrepareLookupTableFrameTable(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	original, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	var wantFrames [][]byte
+	for frame := range original.VideoFrames() {
+		r, err := original.VideoFrameData(frame)
+		if err != nil {
+			t.Fatalf("Failed to get video data stream: %v.", err)
+		}
+		frameData, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read video data stream: %v.", err)
+		}
+		wantFrames = append(wantFrames, frameData)
+	}
+
+	// Rename the last VFTE chunk, so that the lookup list is missing a video frame.
+	i := bytes.LastIndex(data, []byte("VFTE"))
+	if i < 0 {
+		t.Fatalf("Couldn't find VFTE chunk.")
+	}
+	copy(data[i:], "XXXX")
+
+	mxvReader, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	if err := mxvReader.PrepareLookupTable(); err != nil {
+		t.Fatalf("Failed to prepare lookup table: %v.", err)
+	}
+	if warnings := mxvReader.Warnings(); len(warnings) != 1 || warnings[0].Check != mxv.CheckLookupList {
+		t.Errorf("Expected a single %s warning, got %v.", mxv.CheckLookupList, warnings)
+	}
+
+	for frame := range mxvReader.VideoFrames() {
+		r, err := mxvReader.VideoFrameData(frame)
+		if err != nil {
+			t.Fatalf("Failed to get video data stream: %v.", err)
+		}
+		frameData, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read video data stream: %v.", err)
+		}
+		if !bytes.Equal(frameData, wantFrames[frame]) {
+			t.Errorf("Video frame %d differs from the original. Got %d bytes, want %d bytes.", frame, len(frameData), len(wantFrames[frame]))
+		}
+	}
+
+	var audioFrames uint64
+	for range mxvReader.AudioFrames() {
+		audioFrames++
+	}
+	if audioFrames != mxvReader.Info.AudioFrames {
+		t.Errorf("Unexpected number of audio frames. Got %d, want %d.", audioFrames, mxvReader.Info.AudioFrames)
+	}
+}
+
+func TestCheckFrameTable(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	mxvReader, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	disagreements, err := mxvReader.CheckFrameTable()
+	if err != nil {
+		t.Fatalf("Failed to check frame table: %v.", err)
+	}
+	if len(disagreements) != 0 {
+		t.Errorf("Unexpected disagreements %v.", disagreements)
+	}
+
+	// Modify the offset of the second frame in the frame table.
+	i := bytes.Index(data, []byte("MXJVFT64"))
+	if i < 0 {
+		t.Fatalf("Couldn't find MXJVFT64 chunk.")
+	}
+	entry := data[i+16+8:]
+	want := int64(binary.LittleEndian.Uint64(entry))
+	binary.LittleEndian.PutUint64(entry, 1234)
+
+	mxvReader, err = mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	disagreements, err = mxvReader.CheckFrameTable()
+	if err != nil {
+		t.Fatalf("Failed to check frame table: %v.", err)
+	}
+	wantDisagreements := []mxv.FrameTableDisagreement{{Frame: 1, FrameTableOffset: 1234, LookupListOffset: want}}
+	if !cmp.Equal(wantDisagreements, disagreements) {
+		t.Errorf("Unexpected disagreements:\n%s", cmp.Diff(wantDisagreements, disagreements))
+	}
+
+	report, err := mxv.Validate(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to validate file: %v.", err)
+	}
+	wantFindings := []mxv.Finding{{Severity: mxv.SeverityError, Check: mxv.CheckFrameTable, Offset: int64(i), Expected: want, Actual: int64(1234)}}
+	if !cmp.Equal(wantFindings, report.Findings, cmpopts.IgnoreFields(mxv.Finding{}, "Message")) {
+		t.Errorf("Unexpected findings:\n%s", cmp.Diff(wantFindings, report.Findings, cmpopts.IgnoreFields(mxv.Finding{}, "Message")))
+	}
+}
+
+// TestNewReaderRecover checks that the frame index of a truncated file can be recovered from the MXJVFL64 frame list.
+func TestNewReaderRecover(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	original, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	var wantVFTEs []mxriff64.Chunk32VFTEData
+	for _, vfte := range original.VideoFrames() {
+		wantVFTEs = append(wantVFTEs, vfte)
+	}
+
+	// Truncate the file in the middle of the MXJVVF64 chunk of frame 20.
+	const wantVideoFrames = 20
+	full := data
+	data = data[:wantVFTEs[wantVideoFrames].VideoFrameChunkOffset+100]
+	wantVFTEs = wantVFTEs[:wantVideoFrames]
+
+	var wantAFTEs []mxriff64.Chunk32AFTEData
+	var wantAudioSamples uint64
+	for _, afte := range original.AudioFrames() {
+		if afte.AudioFrameChunkOffset+int64(afte.AudioFrameChunkSize) <= int64(len(data)) {
+			wantAFTEs = append(wantAFTEs, afte)
+			wantAudioSamples = afte.StartSample + uint64(afte.Samples)
+		}
+	}
+
+	if _, err := mxv.NewReader(bytes.NewReader(data)); err == nil {
+		t.Errorf("Expected an error when reading a truncated file without recovery.")
+	}
+
+	mxvReader, err := mxv.NewReaderWithOptions(bytes.NewReader(data), mxv.ReaderOptions{Recover: true})
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	if mxvReader.Info.VideoFrames != wantVideoFrames || mxvReader.Info.AudioFrames != uint64(len(wantAFTEs)) || mxvReader.Info.AudioSamples != wantAudioSamples {
+		t.Errorf("Unexpected recovered counts. Got %d video frames, %d audio frames and %d audio samples, want %d, %d and %d.",
+			mxvReader.Info.VideoFrames, mxvReader.Info.AudioFrames, mxvReader.Info.AudioSamples, wantVideoFrames, len(wantAFTEs), wantAudioSamples)
+	}
+
+	var gotVFTEs []mxriff64.Chunk32VFTEData
+	for _, vfte := range mxvReader.VideoFrames() {
+		gotVFTEs = append(gotVFTEs, vfte)
+	}
+	if !cmp.Equal(wantVFTEs, gotVFTEs) {
+		t.Errorf("Recovered video frames differ from lookup list:\n%s", cmp.Diff(wantVFTEs, gotVFTEs))
+	}
+
+	var gotAFTEs []mxriff64.Chunk32AFTEData
+	for _, afte := range mxvReader.AudioFrames() {
+		gotAFTEs = append(gotAFTEs, afte)
+	}
+	if !cmp.Equal(wantAFTEs, gotAFTEs) {
+		t.Errorf("Recovered audio frames differ from lookup list:\n%s", cmp.Diff(wantAFTEs, gotAFTEs))
+	}
+
+	for frame := range mxvReader.VideoFrames() {
+		if _, err := mxvReader.VideoFrameData(frame); err != nil {
+			t.Errorf("Failed to get video data stream of frame %d: %v.", frame, err)
+		}
+	}
+
+	// The size that is passed to NewReaderAtWithOptions limits the recovered frames, even if there is more data.
+	mxvReader, err = mxv.NewReaderAtWithOptions(bytes.NewReader(full), int64(len(data)), mxv.ReaderOptions{Recover: true})
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	gotVFTEs = nil
+	for _, vfte := range mxvReader.VideoFrames() {
+		gotVFTEs = append(gotVFTEs, vfte)
+	}
+	if !cmp.Equal(wantVFTEs, gotVFTEs) {
+		t.Errorf("Recovered video frames of io.ReaderAt differ from lookup list:\n%s", cmp.Diff(wantVFTEs, gotVFTEs))
+	}
+}
+
+// TestNewReaderWithOptionsAudioContinuity checks the handling of gaps and overlaps in the audio data.
+func TestNewReaderWithOptionsAudioContinuity(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "synthetic.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	mxvWriter, err := mxv.NewWriter(f, mxv.Info{
+		ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 16, FrameHeight: 8, Framerate: 25,
+		HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 1, AudioSampleRate: 48000, AudioByteRate: 96000, AudioBytesPerSample: 2, AudioChannelBitDepth: 16,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MXV writer: %v.", err)
+	}
+	if err := mxvWriter.WriteVideoFrame(bytes.NewReader([]byte{0xFF, 0xD8, 0xFF, 0xD9})); err != nil {
+		t.Fatalf("Failed to write video frame: %v.", err)
+	}
+	audioFrames := [][]byte{{1, 1, 1, 1, 1, 1, 1, 1}, {2, 2, 2, 2, 2, 2, 2, 2}, {3, 3, 3, 3, 4, 4, 4, 4}}
+	for _, frame := range audioFrames {
+		if err := mxvWriter.WriteAudioFrame(bytes.NewReader(frame), 4); err != nil {
+			t.Fatalf("Failed to write audio frame: %v.", err)
+		}
+	}
+	if err := mxvWriter.Close(); err != nil {
+		t.Fatalf("Failed to close MXV writer: %v.", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	// Move the second audio frame from sample 4 to 6, which results in a gap of 2 samples and an overlap of 2 samples.
+	afte := bytes.Index(data, []byte("AFTE"))
+	afte += bytes.Index(data[afte+1:], []byte("AFTE")) + 1
+	af64 := bytes.Index(data, []byte("MXJVAF64"))
+	af64 += bytes.Index(data[af64+1:], []byte("MXJVAF64")) + 1
+	binary.LittleEndian.PutUint64(data[afte+8+12:], 6)
+	binary.LittleEndian.PutUint64(data[af64+16+4:], 6)
+
+	readAudio := func(t *testing.T, options mxv.ReaderOptions) (*mxv.Reader, []byte) {
+		mxvReader, err := mxv.NewReaderWithOptions(bytes.NewReader(data), options)
+		if err != nil {
+			t.Fatalf("Failed to read MXV file: %v.", err)
+		}
+		if err := mxvReader.PrepareLookupTable(); err != nil {
+			t.Fatalf("Failed to prepare lookup table: %v.", err)
+		}
+		var audioData []byte
+		for frame := range mxvReader.AudioFrames() {
+			r, _, _, err := mxvReader.AudioFrameData(frame)
+			if err != nil {
+				t.Fatalf("Failed to get audio data stream: %v.", err)
+			}
+			frameData, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("Failed to read audio data stream: %v.", err)
+			}
+			audioData = append(audioData, frameData...)
+		}
+		return mxvReader, audioData
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		mxvReader, err := mxv.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Failed to read MXV file: %v.", err)
+		}
+		err = mxvReader.PrepareLookupTable()
+		if !errors.Is(err, mxv.ErrAudioGap) {
+			t.Fatalf("Expected an error because of the audio gap, got %v.", err)
+		}
+		var checkErr *mxv.CheckError
+		if !errors.As(err, &checkErr) {
+			t.Fatalf("Expected a *CheckError, got %T.", err)
+		}
+		if checkErr.Check != mxv.CheckAudioContinuity || checkErr.Identifier != "MXJVAF64" || checkErr.Offset != int64(af64) || checkErr.Frame != 1 {
+			t.Errorf("Unexpected error details %+v.", *checkErr)
+		}
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		mxvReader, audioData := readAudio(t, mxv.ReaderOptions{Policies: map[mxv.Check]mxv.Policy{mxv.CheckAudioContinuity: mxv.PolicyWarn}})
+		if want := bytes.Join(audioFrames, nil); !bytes.Equal(audioData, want) {
+			t.Errorf("Unexpected audio data. Got %v, want %v.", audioData, want)
+		}
+		if warnings := mxvReader.Warnings(); len(warnings) != 2 {
+			t.Errorf("Unexpected warnings %v.", warnings)
+		}
+
+		// The audio stream places the frames at their start sample, which results in the same data as repairing.
+		stream, err := mxvReader.AudioStream()
+		if err != nil {
+			t.Fatalf("Failed to get audio stream: %v.", err)
+		}
+		streamData, err := io.ReadAll(stream)
+		if err != nil {
+			t.Fatalf("Failed to read audio stream: %v.", err)
+		}
+		if want := slices.Concat(audioFrames[0], []byte{0, 0, 0, 0}, audioFrames[1], audioFrames[2][4:]); !bytes.Equal(streamData, want) {
+			t.Errorf("Unexpected audio stream data. Got %v, want %v.", streamData, want)
+		}
+		if _, err := stream.SeekSample(7); err != nil {
+			t.Fatalf("Failed to seek to sample 7: %v.", err)
+		}
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(stream, buf); err != nil || !bytes.Equal(buf, audioFrames[1][2:6]) {
+			t.Errorf("Unexpected data at sample 7. Got %v, %v, want %v.", buf, err, audioFrames[1][2:6])
+		}
+	})
+
+	t.Run("repair", func(t *testing.T) {
+		mxvReader, audioData := readAudio(t, mxv.ReaderOptions{Policies: map[mxv.Check]mxv.Policy{mxv.CheckAudioContinuity: mxv.PolicyRepair}})
+		want := slices.Concat(audioFrames[0], []byte{0, 0, 0, 0}, audioFrames[1], audioFrames[2][4:])
+		if !bytes.Equal(audioData, want) {
+			t.Errorf("Unexpected audio data. Got %v, want %v.", audioData, want)
+		}
+		if mxvReader.Info.AudioFrames != 4 || mxvReader.Info.AudioSamples != 12 {
+			t.Errorf("Unexpected audio counts. Got %d frames and %d samples, want %d frames and %d samples.", mxvReader.Info.AudioFrames, mxvReader.Info.AudioSamples, 4, 12)
+		}
+		wantWarnings := []mxv.Finding{
+			{Severity: mxv.SeverityWarning, Check: mxv.CheckAudioContinuity, Offset: int64(af64), Expected: uint64(4), Actual: uint64(6)},
+			{Severity: mxv.SeverityWarning, Check: mxv.CheckAudioContinuity, Offset: int64(bytes.LastIndex(data, []byte("MXJVAF64"))), Expected: uint64(10), Actual: uint64(8)},
+		}
+		if warnings := mxvReader.Warnings(); !cmp.Equal(wantWarnings, warnings, cmpopts.IgnoreFields(mxv.Finding{}, "Message")) {
+			t.Errorf("Unexpected warnings:\n%s", cmp.Diff(wantWarnings, warnings, cmpopts.IgnoreFields(mxv.Finding{}, "Message")))
+		}
+	})
+}
+
+func TestNewReaderWithOptionsHeaderMismatch(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	// Change the framerate in the MXJVHD64 chunk, which is at offset 40 of the chunk data.
+	i := bytes.Index(data, identifierMXJVHD64[:])
+	if i < 0 {
+		t.Fatalf("Couldn't find MXJVHD64 chunk.")
+	}
+	binary.LittleEndian.PutUint64(data[i+16+40:], math.Float64bits(50))
+
+	_, err = mxv.NewReader(bytes.NewReader(data))
+	var checkErr *mxv.CheckError
+	if !errors.As(err, &checkErr) || !errors.Is(err, mxv.ErrHeaderMismatch) {
+		t.Errorf("Expected a *CheckError because of contradicting video headers, got %v.", err)
+	} else if checkErr.Identifier != "MXJVHD64" || checkErr.Offset != int64(i) || checkErr.Frame != -1 {
+		t.Errorf("Unexpected error details %+v.", *checkErr)
+	}
+
+	mxvReader, err := mxv.NewReaderWithOptions(bytes.NewReader(data), mxv.ReaderOptions{Policies: map[mxv.Check]mxv.Policy{mxv.CheckHeaderMismatch: mxv.PolicyWarn}})
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	if mxvReader.Info.Framerate != 25 {
+		t.Errorf("Unexpected framerate. Got %v, want %v.", mxvReader.Info.Framerate, 25)
+	}
+	if warnings := mxvReader.Warnings(); len(warnings) != 1 || warnings[0].Check != mxv.CheckHeaderMismatch || warnings[0].Offset != int64(i) {
+		t.Errorf("Unexpected warnings %v.", warnings)
+	}
+}
+
+// TestNewReaderAtConcurrent reads all frames from several goroutines at once.
+func TestNewReaderAtConcurrent(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "29.97i.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	// PrepareLookupTable is not called on purpose, the reader has to be usable right away.
+	mxvReader, err := mxv.NewReaderAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	// Get the expected frame data from a sequential reader.
+	sequentialReader, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	var wantFrames [][]byte
+	for frame := range sequentialReader.VideoFrames() {
+		r, err := sequentialReader.VideoFrameData(frame)
+		if err != nil {
+			t.Fatalf("Failed to get video data stream: %v.", err)
+		}
+		frameData, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read video data stream: %v.", err)
+		}
+		wantFrames = append(wantFrames, frameData)
+	}
+
+	var wg sync.WaitGroup
+	for worker := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Request all readers first, and read them afterwards in reverse order.
+			// This only works if the returned readers are independent of each other.
+			var readers []io.Reader
+			for frame := range len(wantFrames) {
+				r, err := mxvReader.VideoFrameData((frame + worker) % len(wantFrames))
+				if err != nil {
+					t.Errorf("Failed to get video data stream: %v.", err)
+					return
+				}
+				readers = append(readers, r)
+
+				// Info is read without locking, so it must not change while frames are requested.
+				if _, _, err := mxvReader.VideoFrameTimestamp(frame, 90000); err != nil {
+					t.Errorf("Failed to get timestamp of video frame %d: %v.", frame, err)
+					return
+				}
+			}
+			for i := len(readers) - 1; i >= 0; i-- {
+				frame := (i + worker) % len(wantFrames)
+				frameData, err := io.ReadAll(readers[i])
+				if err != nil {
+					t.Errorf("Failed to read video data stream: %v.", err)
+					return
+				}
+				if !bytes.Equal(frameData, wantFrames[frame]) {
+					t.Errorf("Video frame %d differs. Got %d bytes, want %d bytes.", frame, len(frameData), len(wantFrames[frame]))
+				}
+			}
+
+			for frame := range mxvReader.AudioFrames() {
+				r, _, _, err := mxvReader.AudioFrameData(frame)
+				if err != nil {
+					t.Errorf("Failed to get audio data stream: %v.", err)
+					return
+				}
+				if _, err := io.Copy(io.Discard, r); err != nil {
+					t.Errorf("Failed to read audio data stream: %v.", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestAudioFrameFromSample(t *testing.T) {
+	file, err := os.Open(filepath.Join("..", "example-files", "23.976p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer file.Close()
+
+	mxvReader, err := mxv.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	for frame, afte := range mxvReader.AudioFrames() {
+		for _, sample := range []uint64{afte.StartSample, afte.StartSample + uint64(afte.Samples)/2, afte.StartSample + uint64(afte.Samples) - 1} {
+			gotFrame, gotSamples, err := mxvReader.AudioFrameFromSample(sample)
+			if err != nil {
+				t.Fatalf("Failed to get audio frame of sample %d: %v.", sample, err)
+			}
+			if gotFrame != frame || gotSamples != afte.Samples {
+				t.Errorf("Unexpected audio frame for sample %d. Got frame %d with %d samples, want frame %d with %d samples.", sample, gotFrame, gotSamples, frame, afte.Samples)
+			}
+		}
+	}
+
+	if _, _, err := mxvReader.AudioFrameFromSample(mxvReader.Info.AudioSamples); err == nil {
+		t.Errorf("Expected error for sample %d beyond the end.", mxvReader.Info.AudioSamples)
+	}
+}
+
+func TestVideoFrameAtAudioSampleAt(t *testing.T) {
+	file, err := os.Open(filepath.Join("..", "example-files", "29.97p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer file.Close()
+
+	mxvReader, err := mxv.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	tests := []struct {
+		time   time.Duration
+		frame  int
+		sample uint64
+	}{
+		{0, 0, 0},
+		{33 * time.Millisecond, 0, 1584},
+		{34 * time.Millisecond, 1, 1632},
+		{time.Second, 29, 48000},
+		{1001 * time.Millisecond, 30, 48048},
+	}
+
+	for _, test := range tests {
+		frame, err := mxvReader.VideoFrameAt(test.time)
+		if err != nil {
+			t.Errorf("Failed to get video frame at %v: %v.", test.time, err)
+		} else if frame != test.frame {
+			t.Errorf("Unexpected video frame at %v. Got %d, want %d.", test.time, frame, test.frame)
+		}
+
+		sample, err := mxvReader.AudioSampleAt(test.time)
+		if err != nil {
+			t.Errorf("Failed to get audio sample at %v: %v.", test.time, err)
+		} else if sample != test.sample {
+			t.Errorf("Unexpected audio sample at %v. Got %d, want %d.", test.time, sample, test.sample)
+		}
+	}
+
+	if _, err := mxvReader.VideoFrameAt(time.Hour); err == nil {
+		t.Errorf("Expected error for time beyond the last video frame.")
+	}
+	if _, err := mxvReader.AudioSampleAt(-time.Second); err == nil {
+		t.Errorf("Expected error for negative time.")
+	}
+}
+
+func TestVideoFrameTimestamp(t *testing.T) {
+	file, err := os.Open(filepath.Join("..", "example-files", "29.97p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer file.Close()
+
+	mxvReader, err := mxv.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	if num, den := mxvReader.Info.FramerateFraction(); num != 30000 || den != 1001 {
+		t.Fatalf("Unexpected framerate. Got %d/%d, want %d/%d.", num, den, 30000, 1001)
+	}
+
+	// The timestamps in milliseconds are rounded, but the durations add up to the exact length.
+	var wantPTS uint64
+	for frame := range mxvReader.VideoFrames() {
+		pts, duration, err := mxvReader.VideoFrameTimestamp(frame, 1000)
+		if err != nil {
+			t.Fatalf("Failed to get timestamp of frame %d: %v.", frame, err)
+		}
+		if pts != wantPTS {
+			t.Errorf("Unexpected timestamp of frame %d. Got %d, want %d.", frame, pts, wantPTS)
+		}
+		if duration != 33 && duration != 34 {
+			t.Errorf("Unexpected duration of frame %d: %d.", frame, duration)
+		}
+		wantPTS = pts + duration
+	}
+	if want := uint64(2002); wantPTS != want {
+		t.Errorf("Unexpected total length. Got %d ms, want %d ms.", wantPTS, want)
+	}
+
+	if pts, duration, err := mxvReader.VideoFrameTimestamp(10, 30000); err != nil || pts != 10010 || duration != 1001 {
+		t.Errorf("Unexpected timestamp of frame 10. Got %d, %d, %v, want %d, %d, %v.", pts, duration, err, 10010, 1001, nil)
+	}
+	if _, _, err := mxvReader.VideoFrameTimestamp(int(mxvReader.Info.VideoFrames), 1000); err == nil {
+		t.Errorf("Expected error for frame beyond the end.")
+	}
+}
+
+func TestReaderRawHeaders(t *testing.T) {
+	file, err := os.Open(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer file.Close()
+
+	mxvReader, err := mxv.NewReader(file)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	headers := mxvReader.RawHeaders()
+	if headers.MXJVH264 == nil || headers.MXJVHD64 == nil || headers.MXWFMT64 == nil || headers.MXJVCO64 == nil || headers.MXJVPD64 == nil {
+		t.Fatalf("Missing header chunk data: %+v.", headers)
+	}
+	if headers.MXJVH264.Chunk64MXJVHD64Data != *headers.MXJVHD64 {
+		t.Errorf("The two video headers differ. Got %+v and %+v.", headers.MXJVH264.Chunk64MXJVHD64Data, *headers.MXJVHD64)
+	}
+	if got := headers.MXJVHD64.StructSize; got != 112 {
+		t.Errorf("Unexpected StructSize. Got %d, want %d.", got, 112)
+	}
+
+	wantCO64 := mxriff64.Chunk64MXJVCO64Data{GUID: [16]byte{0x7f, 0x74, 0x96, 0xb5, 0xe3, 0x71, 0x87, 0x4d, 0x9a, 0x8f, 0x1c, 0x69, 0xea, 0xa3, 0xe4, 0x16}}
+	if *headers.MXJVCO64 != wantCO64 {
+		t.Errorf("Unexpected MXJVCO64 data. Got %+v, want %+v.", *headers.MXJVCO64, wantCO64)
+	}
+	wantPD64 := mxriff64.Chunk64MXJVPD64Data{StructSize: 16, Unknown1: 2, BitDepth: 24, Unknown3: 239, Unknown4: 1}
+	if *headers.MXJVPD64 != wantPD64 {
+		t.Errorf("Unexpected MXJVPD64 data. Got %+v, want %+v.", *headers.MXJVPD64, wantPD64)
+	}
+
+	// Modifying the copy must not change the reader.
+	headers.MXJVH264.Framerate = 1
+	if mxvReader.RawHeaders().MXJVH264.Framerate != 25 {
+		t.Errorf("RawHeaders doesn't return a copy.")
+	}
+}
+
+// TestReaderRawHeadersVariants checks that MXJVCO64 and MXJVPD64 chunks with a different length don't prevent a file from being read.
+func TestReaderRawHeadersVariants(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	original, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	originalHeaders := original.RawHeaders()
+
+	// Find the MXJVCO64 chunk, which is directly followed by the MXJVPD64 chunk.
+	identifierMXJVCO64 := mxriff64.Identifier64{'M', 'X', 'J', 'V', 'C', 'O', '6', '4'}
+	identifierMXJVPD64 := mxriff64.Identifier64{'M', 'X', 'J', 'V', 'P', 'D', '6', '4'}
+	chunks := original.Chunks()
+	i := slices.IndexFunc(chunks, func(c mxv.ChunkInfo) bool { return c.Identifier == identifierMXJVCO64 })
+	if i < 0 || i+1 >= len(chunks) || chunks[i+1].Identifier != identifierMXJVPD64 {
+		t.Fatalf("Expected a MXJVCO64 chunk followed by a MXJVPD64 chunk.")
+	}
+	regionStart, regionEnd := chunks[i].Offset, chunks[i+1].Offset+chunks[i+1].Length
+	co64Data := data[chunks[i].Offset+16 : chunks[i].Offset+chunks[i].Length]
+	pd64Data := data[chunks[i+1].Offset+16 : chunks[i+1].Offset+chunks[i+1].Length]
+
+	chunk := func(id mxriff64.Identifier64, data []byte) []byte {
+		result := binary.LittleEndian.AppendUint64(id[:], uint64(len(data)))
+		return append(result, data...)
+	}
+
+	// Both chunks are rewritten in place, one longer and one shorter than known, so that all other chunks keep their offsets.
+	tests := []struct {
+		name     string
+		co64Data []byte
+		pd64Data []byte
+		wantCO64 bool
+		wantPD64 bool
+	}{
+		{"LongerCO64", append(slices.Clone(co64Data), 1, 2, 3, 4), pd64Data[:len(pd64Data)-4], true, false},
+		{"LongerPD64", co64Data[:len(co64Data)-4], append(slices.Clone(pd64Data), 1, 2, 3, 4), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			region := append(chunk(identifierMXJVCO64, tt.co64Data), chunk(identifierMXJVPD64, tt.pd64Data)...)
+			if int64(len(region)) != regionEnd-regionStart {
+				t.Fatalf("The rewritten chunks have a length of %d, want %d.", len(region), regionEnd-regionStart)
+			}
+			modified := slices.Clone(data)
+			copy(modified[regionStart:], region)
+
+			mxvReader, err := mxv.NewReader(bytes.NewReader(modified))
+			if err != nil {
+				t.Fatalf("Failed to read MXV file: %v.", err)
+			}
+			if !cmp.Equal(original.Info, mxvReader.Info) {
+				t.Errorf("Video info differs from original:\n%s", cmp.Diff(original.Info, mxvReader.Info))
+			}
+
+			headers := mxvReader.RawHeaders()
+			if got := headers.MXJVCO64 != nil; got != tt.wantCO64 {
+				t.Errorf("Unexpected presence of MXJVCO64 data. Got %t, want %t.", got, tt.wantCO64)
+			} else if got && *headers.MXJVCO64 != *originalHeaders.MXJVCO64 {
+				t.Errorf("Unexpected MXJVCO64 data. Got %+v, want %+v.", *headers.MXJVCO64, *originalHeaders.MXJVCO64)
+			}
+			if got := headers.MXJVPD64 != nil; got != tt.wantPD64 {
+				t.Errorf("Unexpected presence of MXJVPD64 data. Got %t, want %t.", got, tt.wantPD64)
+			} else if got && *headers.MXJVPD64 != *originalHeaders.MXJVPD64 {
+				t.Errorf("Unexpected MXJVPD64 data. Got %+v, want %+v.", *headers.MXJVPD64, *originalHeaders.MXJVPD64)
+			}
+
+			// The shorter chunk is kept as a dummy chunk.
+			for _, c := range mxvReader.Chunks() {
+				_, isDummy := c.Chunk.(*mxriff64.Chunk64Dummy)
+				if wantDummy := (c.Identifier == identifierMXJVCO64 && !tt.wantCO64) || (c.Identifier == identifierMXJVPD64 && !tt.wantPD64); isDummy != wantDummy {
+					t.Errorf("Chunk %v at offset %d has type %T.", c.Identifier, c.Offset, c.Chunk)
+				}
+			}
+		})
+	}
+}
+
+func TestReaderChunks(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	mxvReader, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	chunks := mxvReader.Chunks()
+	if len(chunks) == 0 {
+		t.Fatalf("No chunks found.")
+	}
+
+	// The chunks follow each other without gaps, and fill the rest of the file.
+	offset := chunks[0].Offset
+	found := map[mxriff64.Identifier64]bool{}
+	for i, chunk := range chunks {
+		if chunk.Offset != offset {
+			t.Errorf("Chunk %d %v starts at %d, want %d.", i, chunk.Identifier, chunk.Offset, offset)
+		}
+		if chunk.Identifier != chunk.Chunk.Identifier() || chunk.Length != chunk.Chunk.Length() {
+			t.Errorf("Chunk %d %v doesn't match its parsed chunk.", i, chunk.Identifier)
+		}
+		if got := mxriff64.Identifier64(data[chunk.Offset : chunk.Offset+8]); got != chunk.Identifier {
+			t.Errorf("Chunk %d has identifier %v in the file, want %v.", i, got, chunk.Identifier)
+		}
+		found[chunk.Identifier] = true
+		offset += chunk.Length
+	}
+	if offset != int64(len(data)) {
+		t.Errorf("The chunks end at %d, want %d.", offset, len(data))
+	}
+
+	for _, id := range []mxriff64.Identifier64{identifierMXJVH264, identifierMXJVHD64, {'M', 'X', 'J', 'V', 'C', 'O', '6', '4'}, {'M', 'X', 'J', 'V', 'P', 'D', '6', '4'}} {
+		if !found[id] {
+			t.Errorf("Missing chunk %v.", id)
+		}
+	}
+}
+
+// TestReaderFrameErrors checks the errors that are returned for damaged or invalid frames.
+func TestReaderFrameErrors(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	original, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	var vftes []mxriff64.Chunk32VFTEData
+	for _, vfte := range original.VideoFrames() {
+		vftes = append(vftes, vfte)
+	}
+
+	// Let the VFTE entry of frame 1 point to the MXJVH264 chunk at offset 24.
+	data = slices.Clone(data)
+	vfte := bytes.Index(data, []byte("VFTE"))
+	vfte += bytes.Index(data[vfte+1:], []byte("VFTE")) + 1
+	binary.LittleEndian.PutUint64(data[vfte+8:], 24)
+
+	// Corrupt the length field of the MXJVVF64 chunk of frame 2.
+	binary.LittleEndian.PutUint64(data[vftes[2].VideoFrameChunkOffset+8:], 1<<40)
+
+	mxvReader, err := mxv.NewReaderWithOptions(bytes.NewReader(data), mxv.ReaderOptions{Policies: map[mxv.Check]mxv.Policy{mxv.CheckChunkSize: mxv.PolicyWarn}})
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	tests := []struct {
+		name       string
+		frame      int
+		wantErr    error
+		wantOffset int64
+	}{
+		{"WrongChunk", 1, mxv.ErrFrameChunk, 24},
+		{"Truncated", 2, mxv.ErrTruncated, vftes[2].VideoFrameChunkOffset},
+		{"Negative", -1, mxv.ErrFrameRange, -1},
+		{"OutOfRange", len(vftes), mxv.ErrFrameRange, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mxvReader.VideoFrameData(tt.frame)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unexpected error %v, want %v.", err, tt.wantErr)
+			}
+			var frameErr *mxv.FrameError
+			if !errors.As(err, &frameErr) {
+				t.Fatalf("Expected a *FrameError, got %T.", err)
+			}
+			if frameErr.Identifier != "MXJVVF64" || frameErr.Frame != tt.frame || frameErr.Offset != tt.wantOffset {
+				t.Errorf("Unexpected error details %+v.", *frameErr)
+			}
+		})
+	}
+
+	if _, err := mxvReader.VideoFrameData(0); err != nil {
+		t.Errorf("Failed to get video data stream of undamaged frame: %v.", err)
+	}
+}
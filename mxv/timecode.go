@@ -0,0 +1,150 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Timecode is a SMPTE timecode in the form HH:MM:SS:FF.
+//
+// Drop-frame timecode skips the first frame numbers of every minute, except for every tenth minute.
+// This keeps the timecode in sync with the wall clock for NTSC rates like 29.97 and 59.94.
+type Timecode struct {
+	Hours, Minutes, Seconds, Frames int
+	DropFrame                       bool
+}
+
+// ParseTimecode parses a timecode in the form HH:MM:SS:FF.
+// Drop-frame timecode is marked with a semicolon or period before the frames, like HH:MM:SS;FF.
+func ParseTimecode(s string) (Timecode, error) {
+	var tc Timecode
+
+	sep := strings.LastIndexAny(s, ":;.")
+	if sep < 0 {
+		return Timecode{}, fmt.Errorf("timecode %q is not in the form HH:MM:SS:FF", s)
+	}
+	tc.DropFrame = s[sep] != ':'
+
+	fields := strings.Split(s[:sep], ":")
+	fields = append(fields, s[sep+1:])
+	if len(fields) != 4 {
+		return Timecode{}, fmt.Errorf("timecode %q is not in the form HH:MM:SS:FF", s)
+	}
+
+	for i, dst := range []*int{&tc.Hours, &tc.Minutes, &tc.Seconds, &tc.Frames} {
+		value, err := strconv.ParseUint(fields[i], 10, 31)
+		if err != nil {
+			return Timecode{}, fmt.Errorf("timecode %q contains an invalid value %q", s, fields[i])
+		}
+		*dst = int(value)
+	}
+
+	return tc, nil
+}
+
+func (tc Timecode) String() string {
+	sep := ':'
+	if tc.DropFrame {
+		sep = ';'
+	}
+	return fmt.Sprintf("%02d:%02d:%02d%c%02d", tc.Hours, tc.Minutes, tc.Seconds, sep, tc.Frames)
+}
+
+// TimecodeRate returns the number of frames per timecode second for the framerate of the file, and whether drop-frame timecode can be used.
+// Drop-frame timecode is only defined for 29.97 and 59.94 frame/s.
+func (i Info) TimecodeRate() (base int, dropFrame bool) {
+	num, den := i.FramerateFraction()
+	if den == 0 {
+		return 0, false
+	}
+
+	base = int((num + den/2) / den)
+	return base, den == 1001 && num == uint64(base)*1000 && (base == 30 || base == 60)
+}
+
+// VideoFrameTimecode returns the timecode of the given video frame, counted from the given start timecode of frame 0.
+// The result uses drop-frame timecode if the start timecode does, and wraps around after 24 hours.
+func (i Info) VideoFrameTimecode(frame uint64, start Timecode) (Timecode, error) {
+	base, dropFrameAllowed := i.TimecodeRate()
+	if base <= 0 {
+		return Timecode{}, fmt.Errorf("invalid framerate %v", i.Framerate)
+	}
+	if start.DropFrame && !dropFrameAllowed {
+		return Timecode{}, fmt.Errorf("drop-frame timecode is not defined for a framerate of %v", i.Framerate)
+	}
+	if err := start.validate(base); err != nil {
+		return Timecode{}, err
+	}
+
+	framesPerDay := uint64(timecodeToFrames(Timecode{Hours: 24, DropFrame: start.DropFrame}, base))
+	return framesToTimecode((uint64(timecodeToFrames(start, base))+frame)%framesPerDay, base, start.DropFrame), nil
+}
+
+// VideoFrameTimecode returns the timecode of the given video frame, counted from the given start timecode of frame 0.
+// See Info.VideoFrameTimecode for details.
+//
+// The range of valid frame numbers is [0...Info.VideoFrames-1].
+func (r *Reader) VideoFrameTimecode(frame int, start Timecode) (Timecode, error) {
+	if frame < 0 || uint64(frame) >= r.Info.VideoFrames {
+		return Timecode{}, frameRangeError("MXJVVF64", frame, int(r.Info.VideoFrames))
+	}
+
+	return r.Info.VideoFrameTimecode(uint64(frame), start)
+}
+
+// droppedFrames returns the number of frame numbers that drop-frame timecode skips at the start of a minute.
+func droppedFrames(base int) int {
+	return base / 15 // 2 for 30 frame/s, 4 for 60 frame/s.
+}
+
+// validate checks that all fields of the timecode are in range for the given number of frames per second.
+func (tc Timecode) validate(base int) error {
+	if tc.Hours < 0 || tc.Hours >= 24 || tc.Minutes < 0 || tc.Minutes >= 60 || tc.Seconds < 0 || tc.Seconds >= 60 || tc.Frames < 0 || tc.Frames >= base {
+		return fmt.Errorf("timecode %v is out of range for %d frames per second", tc, base)
+	}
+	if tc.DropFrame && tc.Seconds == 0 && tc.Minutes%10 != 0 && tc.Frames < droppedFrames(base) {
+		return fmt.Errorf("timecode %v doesn't exist in drop-frame timecode", tc)
+	}
+	return nil
+}
+
+// timecodeToFrames returns the number of frames from 00:00:00:00 to the given timecode.
+func timecodeToFrames(tc Timecode, base int) int {
+	frames := ((tc.Hours*60+tc.Minutes)*60+tc.Seconds)*base + tc.Frames
+	if tc.DropFrame {
+		minutes := tc.Hours*60 + tc.Minutes
+		frames -= droppedFrames(base) * (minutes - minutes/10)
+	}
+	return frames
+}
+
+// framesToTimecode returns the timecode that is the given number of frames after 00:00:00:00.
+func framesToTimecode(frames uint64, base int, dropFrame bool) Timecode {
+	n := int(frames)
+	if dropFrame {
+		// Add the skipped frame numbers back, so that the timecode can be calculated like non-drop-frame timecode.
+		drop := droppedFrames(base)
+		framesPerMinute := base*60 - drop
+		framesPer10Minutes := base*600 - drop*9
+
+		tens, rest := n/framesPer10Minutes, n%framesPer10Minutes
+		n += drop * 9 * tens
+		if rest > drop {
+			n += drop * ((rest - drop) / framesPerMinute)
+		}
+	}
+
+	return Timecode{
+		Hours:     n / (base * 3600),
+		Minutes:   n / (base * 60) % 60,
+		Seconds:   n / base % 60,
+		Frames:    n % base,
+		DropFrame: dropFrame,
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv_test
+
+import (
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+func TestParseTimecode(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    mxv.Timecode
+		wantErr bool
+	}{
+		{"01:00:00:00", mxv.Timecode{Hours: 1}, false},
+		{"12:34:56:07", mxv.Timecode{Hours: 12, Minutes: 34, Seconds: 56, Frames: 7}, false},
+		{"00:01:00;02", mxv.Timecode{Minutes: 1, Frames: 2, DropFrame: true}, false},
+		{"00:01:00.02", mxv.Timecode{Minutes: 1, Frames: 2, DropFrame: true}, false},
+		{"00:01:00", mxv.Timecode{}, true},
+		{"00:01:00:xx", mxv.Timecode{}, true},
+		{"00:-1:00:00", mxv.Timecode{}, true},
+	}
+
+	for _, test := range tests {
+		got, err := mxv.ParseTimecode(test.s)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseTimecode(%q) returned error %v, want error: %t.", test.s, err, test.wantErr)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseTimecode(%q) = %+v, want %+v.", test.s, got, test.want)
+		}
+	}
+}
+
+func TestVideoFrameTimecode(t *testing.T) {
+	info25 := mxv.Info{Framerate: 25, FramerateNum: 25, FramerateDen: 1}
+	info2997 := mxv.Info{Framerate: 29.97, FramerateNum: 30000, FramerateDen: 1001}
+	info5994 := mxv.Info{Framerate: 59.94, FramerateNum: 60000, FramerateDen: 1001}
+
+	tests := []struct {
+		info  mxv.Info
+		frame uint64
+		start string
+		want  string
+	}{
+		{info25, 0, "01:00:00:00", "01:00:00:00"},
+		{info25, 24, "01:00:00:00", "01:00:00:24"},
+		{info25, 25, "01:00:00:00", "01:00:01:00"},
+		{info25, 90000, "23:59:59:24", "00:59:59:24"},
+		{info2997, 1799, "00:00:00:00", "00:00:59:29"},
+		{info2997, 1800, "00:00:00:00", "00:01:00:00"},
+		{info2997, 1799, "00:00:00;00", "00:00:59;29"},
+		{info2997, 1800, "00:00:00;00", "00:01:00;02"},
+		{info2997, 17981, "00:00:00;00", "00:09:59;29"},
+		{info2997, 17982, "00:00:00;00", "00:10:00;00"},
+		{info2997, 107892, "00:00:00;00", "01:00:00;00"},
+		{info2997, 1, "00:00:59;29", "00:01:00;02"},
+		{info5994, 3600, "00:00:00;00", "00:01:00;04"},
+		{info5994, 35964, "00:00:00;00", "00:10:00;00"},
+	}
+
+	for _, test := range tests {
+		start, err := mxv.ParseTimecode(test.start)
+		if err != nil {
+			t.Fatalf("Failed to parse timecode: %v.", err)
+		}
+		got, err := test.info.VideoFrameTimecode(test.frame, start)
+		if err != nil {
+			t.Errorf("Failed to get timecode of frame %d at %v frame/s: %v.", test.frame, test.info.Framerate, err)
+			continue
+		}
+		if got.String() != test.want {
+			t.Errorf("Unexpected timecode of frame %d at %v frame/s starting at %s. Got %s, want %s.", test.frame, test.info.Framerate, test.start, got, test.want)
+		}
+	}
+
+	// Invalid start timecodes.
+	for _, test := range []struct {
+		info  mxv.Info
+		start mxv.Timecode
+	}{
+		{info25, mxv.Timecode{DropFrame: true}},
+		{info25, mxv.Timecode{Frames: 25}},
+		{info25, mxv.Timecode{Hours: 24}},
+		{info2997, mxv.Timecode{Minutes: 1, Frames: 1, DropFrame: true}},
+	} {
+		if _, err := test.info.VideoFrameTimecode(0, test.start); err == nil {
+			t.Errorf("Expected error for start timecode %v at %v frame/s.", test.start, test.info.Framerate)
+		}
+	}
+}
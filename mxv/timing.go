@@ -0,0 +1,115 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Dadido3/mxv-demuxer/internal/fraction"
+)
+
+// exactFramerate recovers the exact framerate from the rounded value in the video header.
+//
+// Candidates are the nearest integer rate, the nearest NTSC rate (n*1000/1001) and the closest fraction with a denominator of at most 1001.
+// They are preferred in this order, unless the audio length contradicts them.
+// A candidate is contradicted if the video and audio length differ by more than one frame.
+func exactFramerate(framerate float64, videoFrames, audioSamples uint64, sampleRate uint32) (num, den uint32) {
+	if !(framerate > 0) || framerate > math.MaxUint32/1001 {
+		return 0, 0
+	}
+
+	type candidate struct{ num, den uint64 }
+	var candidates []candidate
+
+	// The header values are rounded to a few decimal places, so allow a small relative error.
+	const tolerance = 5e-4
+	if n := math.Round(framerate); n > 0 && math.Abs(framerate-n) <= framerate*tolerance {
+		candidates = append(candidates, candidate{uint64(n), 1})
+	}
+	if n := math.Round(framerate * 1.001); n > 0 && math.Abs(framerate-n*1000/1001) <= framerate*tolerance {
+		candidates = append(candidates, candidate{uint64(n) * 1000, 1001})
+	}
+	if n, d := fraction.Approximate(framerate, 1001); n > 0 {
+		candidates = append(candidates, candidate{n, d})
+	}
+	if len(candidates) == 0 {
+		return 0, 0
+	}
+
+	if videoFrames > 0 && audioSamples > 0 && sampleRate > 0 {
+		audioLength := float64(audioSamples) / float64(sampleRate)
+		for _, c := range candidates {
+			frameLength := float64(c.den) / float64(c.num)
+			if math.Abs(float64(videoFrames)*frameLength-audioLength) <= frameLength {
+				return uint32(c.num), uint32(c.den)
+			}
+		}
+	}
+
+	return uint32(candidates[0].num), uint32(candidates[0].den)
+}
+
+// VideoFrameTimestamp returns the presentation timestamp and duration of the given video frame in units of 1/timescale seconds.
+// For example, a timescale of 90000 returns values in the 90 kHz clock of MPEG, and a timescale of Info.AudioSampleRate returns audio sample positions.
+//
+// The timestamps are based on the exact framerate, see Info.FramerateFraction.
+// The range of valid frame numbers is [0...Info.VideoFrames-1].
+func (r *Reader) VideoFrameTimestamp(frame int, timescale uint64) (pts, duration uint64, err error) {
+	if frame < 0 || uint64(frame) >= r.Info.VideoFrames {
+		return 0, 0, frameRangeError("MXJVVF64", frame, int(r.Info.VideoFrames))
+	}
+	if timescale == 0 {
+		return 0, 0, fmt.Errorf("invalid timescale %d", timescale)
+	}
+
+	pts, duration = r.Info.VideoFrameTimestamp(uint64(frame), timescale)
+	return pts, duration, nil
+}
+
+// VideoFrameAt returns the number of the video frame that is displayed at the given time.
+// The time is relative to the start of the video, and the result is based on the exact framerate, see Info.FramerateFraction.
+func (r *Reader) VideoFrameAt(t time.Duration) (frame int, err error) {
+	if t < 0 {
+		return 0, fmt.Errorf("negative time %v", t)
+	}
+	num, den := r.Info.FramerateFraction()
+	if num == 0 {
+		return 0, fmt.Errorf("invalid framerate %v", r.Info.Framerate)
+	}
+
+	// Split into seconds and the remainder, so that the multiplication can't overflow.
+	seconds, remainder := uint64(t/time.Second), uint64(t%time.Second)
+	f := (seconds*num + remainder*num/uint64(time.Second)) / den
+	if f >= r.Info.VideoFrames {
+		return 0, fmt.Errorf("time %v is beyond the last of %d video frames", t, r.Info.VideoFrames)
+	}
+
+	return int(f), nil
+}
+
+// AudioSampleAt returns the index of the audio sample that is played at the given time.
+// The time is relative to the start of the audio, and the result is based on Info.AudioSampleRate.
+//
+// Use AudioFrameFromSample to get the audio frame that contains the sample.
+func (r *Reader) AudioSampleAt(t time.Duration) (sample uint64, err error) {
+	if t < 0 {
+		return 0, fmt.Errorf("negative time %v", t)
+	}
+	if !r.Info.HasAudio || r.Info.AudioSampleRate == 0 {
+		return 0, ErrNoAudio
+	}
+
+	// Split into seconds and the remainder, so that the multiplication can't overflow.
+	seconds, remainder := uint64(t/time.Second), uint64(t%time.Second)
+	sample = seconds*uint64(r.Info.AudioSampleRate) + remainder*uint64(r.Info.AudioSampleRate)/uint64(time.Second)
+	if sample >= r.Info.AudioSamples {
+		return 0, fmt.Errorf("time %v is beyond the last of %d audio samples", t, r.Info.AudioSamples)
+	}
+
+	return sample, nil
+}
@@ -0,0 +1,362 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// Severity describes how serious a finding is.
+type Severity int
+
+const (
+	SeverityWarning Severity = iota // A problem that most likely doesn't affect the extracted data, like wrong statistics in the header.
+	SeverityError                   // A problem that causes the reader to fail, or that results in damaged output.
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// Check identifies a single validation check.
+type Check string
+
+const (
+	CheckHeaderMismatch    Check = "HeaderMismatch"    // A field differs between the MXJVH264 and MXJVHD64 video headers.
+	CheckLookupList        Check = "LookupList"        // The MXJVTL32 lookup list is missing or can't be read completely.
+	CheckFrameTable        Check = "FrameTable"        // The MXJVFT64 frame table disagrees with the VFTE entries of the MXJVTL32 lookup list.
+	CheckWaveFormat        Check = "WaveFormat"        // The MXWFMT64 audio format chunk is missing, even though the video header flags audio.
+	CheckVideoFrameCount   Check = "VideoFrameCount"   // The number of VFTE entries differs from the header.
+	CheckAudioFrameCount   Check = "AudioFrameCount"   // The number of AFTE entries differs from the header.
+	CheckAudioSampleCount  Check = "AudioSampleCount"  // The number of audio samples differs from the header.
+	CheckAudioContinuity   Check = "AudioContinuity"   // There is a gap or overlap between two audio frames.
+	CheckFrameChunk        Check = "FrameChunk"        // A lookup entry doesn't point to a readable chunk of the correct type.
+	CheckChunkSize         Check = "ChunkSize"         // The chunk size in a lookup entry differs from the actual chunk size.
+	CheckZeroChunkSize     Check = "ZeroChunkSize"     // The chunk size in a lookup entry is zero. This seems to happen in older MXV files.
+	CheckAudioFrameData    Check = "AudioFrameData"    // An AFTE entry differs from the MXJVAF64 chunk it points to, or the chunk contains the wrong amount of data.
+	CheckJPEGMarkers       Check = "JPEGMarkers"       // The JPEG data doesn't start with a SOI or doesn't end with an EOI marker.
+	CheckMaxJPEGSize       Check = "MaxJPEGSize"       // The MaxJPEGSize header field differs from the size of the largest MXJVVF64 chunk.
+	CheckMaxAudioChunkSize Check = "MaxAudioChunkSize" // The MaxAudioChunkSize header field differs from the size of the largest MXJVAF64 chunk.
+	CheckMaxReadSize       Check = "MaxReadSize"       // The MaxReadSize header field differs from the largest video and audio frame chunk pair.
+)
+
+// Finding describes a single problem found by Validate.
+type Finding struct {
+	Severity Severity
+	Check    Check
+	Offset   int64 // File offset of the chunk the finding refers to, or -1 if it doesn't refer to a specific chunk.
+	Expected any   // The expected value, or nil if there is none.
+	Actual   any   // The actual value, or nil if there is none.
+	Message  string
+}
+
+func (f Finding) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s: %s", f.Severity, f.Check, f.Message)
+	if f.Offset >= 0 {
+		fmt.Fprintf(&sb, " (at offset %d)", f.Offset)
+	}
+	if f.Expected != nil || f.Actual != nil {
+		fmt.Fprintf(&sb, ". Got %v, want %v", f.Actual, f.Expected)
+	}
+	return sb.String()
+}
+
+// Report contains the findings of Validate.
+type Report struct {
+	Findings []Finding
+}
+
+// add appends a new finding to the report.
+func (rep *Report) add(severity Severity, check Check, offset int64, expected, actual any, format string, a ...any) {
+	rep.Findings = append(rep.Findings, Finding{Severity: severity, Check: check, Offset: offset, Expected: expected, Actual: actual, Message: fmt.Sprintf(format, a...)})
+}
+
+// HasErrors returns whether the report contains any finding with SeverityError.
+func (rep *Report) HasErrors() bool {
+	for _, f := range rep.Findings {
+		if f.Severity >= SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func (rep *Report) String() string {
+	var sb strings.Builder
+	for _, f := range rep.Findings {
+		sb.WriteString(f.String())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// Validate runs all checks on the MXV file in rs, and returns a report with every problem that was found.
+//
+// In contrast to NewReader and PrepareLookupTable, this doesn't stop at the first problem.
+// An error is only returned if the file can't be parsed at all.
+func Validate(rs io.ReadSeeker) (*Report, error) {
+	// The checks of the reader only record warnings, so that damaged files can still be opened.
+	// The problems are found and reported by the validate methods below, which don't depend on the reader's warnings.
+	policies := map[Check]Policy{}
+	for _, check := range []Check{CheckHeaderMismatch, CheckLookupList, CheckWaveFormat, CheckVideoFrameCount, CheckAudioFrameCount, CheckAudioSampleCount, CheckAudioContinuity, CheckChunkSize} {
+		policies[check] = PolicyWarn
+	}
+	r, err := NewReaderWithOptions(rs, ReaderOptions{Policies: policies})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MXV file: %w", err)
+	}
+
+	rep := &Report{}
+	r.validateHeaders(rep)
+	if r.Info.Flags.HasAudio() && r.chunkWaveFormat == nil {
+		rep.add(SeverityError, CheckWaveFormat, -1, nil, nil, "missing MXWFMT64 chunk, even though the video header flags audio")
+	}
+
+	vftes, aftes, err := r.readLookupTable()
+	if err != nil {
+		rep.add(SeverityError, CheckLookupList, -1, nil, nil, "failed to read lookup list: %v", err)
+	}
+
+	r.validateFrameTable(rep)
+	r.validateCounts(rep, vftes, aftes)
+	videoChunkSizes := r.validateVideoFrames(rep, vftes)
+	audioChunkSizes := r.validateAudioFrames(rep, aftes)
+	r.validateMaxSizes(rep, videoChunkSizes, audioChunkSizes)
+
+	return rep, nil
+}
+
+// validateHeaders compares all fields of the two video headers.
+func (r *Reader) validateHeaders(rep *Report) {
+	if r.chunkVideoHeader == nil || r.chunkVideoHeader2 == nil {
+		return
+	}
+
+	expected := reflect.ValueOf(r.chunkVideoHeader2.Data.Chunk64MXJVHD64Data)
+	actual := reflect.ValueOf(r.chunkVideoHeader.Data)
+	for i := range expected.NumField() {
+		if e, a := expected.Field(i).Interface(), actual.Field(i).Interface(); e != a {
+			rep.add(SeverityError, CheckHeaderMismatch, r.chunkVideoHeader.Offset(), e, a, "field %s of MXJVHD64 differs from MXJVH264", expected.Type().Field(i).Name)
+		}
+	}
+}
+
+// validateFrameTable compares the MXJVFT64 frame table with the MXJVTL32 lookup list, see CheckFrameTable.
+// Nothing is checked if any of the two is missing, as the frame table is optional, and a missing lookup list is already reported.
+func (r *Reader) validateFrameTable(rep *Report) {
+	if r.chunkFrameTable == nil || r.chunkLookupList == nil {
+		return
+	}
+
+	disagreements, err := r.CheckFrameTable()
+	if err != nil {
+		rep.add(SeverityError, CheckFrameTable, r.chunkFrameTable.Offset(), nil, nil, "failed to compare frame table with lookup list: %v", err)
+		return
+	}
+	for _, d := range disagreements {
+		rep.add(SeverityError, CheckFrameTable, r.chunkFrameTable.Offset(), d.LookupListOffset, d.FrameTableOffset, "frame table entry of video frame %d differs from lookup list", d.Frame)
+	}
+}
+
+// validateCounts compares the lookup entries with the frame and sample counts of the header, and checks the audio for gaps and overlaps.
+func (r *Reader) validateCounts(rep *Report, vftes []mxriff64.Chunk32VFTEData, aftes []mxriff64.Chunk32AFTEData) {
+	if r.Info.VideoFrames != uint64(len(vftes)) {
+		rep.add(SeverityError, CheckVideoFrameCount, -1, r.Info.VideoFrames, uint64(len(vftes)), "number of VFTE entries differs from header value")
+	}
+
+	if !r.Info.HasAudio {
+		return
+	}
+
+	if r.Info.AudioFrames != uint64(len(aftes)) {
+		rep.add(SeverityError, CheckAudioFrameCount, -1, r.Info.AudioFrames, uint64(len(aftes)), "number of AFTE entries differs from header value")
+	}
+
+	var audioSamples uint64
+	for frame, afte := range aftes {
+		switch {
+		case afte.StartSample > audioSamples:
+			rep.add(SeverityError, CheckAudioContinuity, afte.AudioFrameChunkOffset, audioSamples, afte.StartSample, "gap of %d samples before audio frame %d", afte.StartSample-audioSamples, frame)
+		case afte.StartSample < audioSamples:
+			rep.add(SeverityError, CheckAudioContinuity, afte.AudioFrameChunkOffset, audioSamples, afte.StartSample, "overlap of %d samples at audio frame %d", audioSamples-afte.StartSample, frame)
+		}
+		audioSamples = max(audioSamples, afte.StartSample+uint64(afte.Samples))
+	}
+	if audioSamples != r.Info.AudioSamples {
+		rep.add(SeverityError, CheckAudioSampleCount, -1, r.Info.AudioSamples, audioSamples, "number of audio samples differs from header value")
+	}
+}
+
+// validateVideoFrames checks all MXJVVF64 chunks referenced by the given VFTE entries.
+// It returns the actual chunk size for every entry, or 0 if the chunk couldn't be read.
+func (r *Reader) validateVideoFrames(rep *Report, vftes []mxriff64.Chunk32VFTEData) []int64 {
+	chunkSizes := make([]int64, len(vftes))
+	checked := map[int64]int64{} // Chunk sizes of already checked chunks by their offset.
+
+	for frame, vfte := range vftes {
+		if vfte.VideoFrameChunkSize == 0 {
+			rep.add(SeverityWarning, CheckZeroChunkSize, vfte.VideoFrameChunkOffset, nil, nil, "VFTE entry of video frame %d has a chunk size of zero", frame)
+		}
+
+		chunkSize, ok := checked[vfte.VideoFrameChunkOffset]
+		if !ok {
+			chunkSize = r.validateVideoFrameChunk(rep, frame, vfte.VideoFrameChunkOffset)
+			checked[vfte.VideoFrameChunkOffset] = chunkSize
+		}
+		chunkSizes[frame] = chunkSize
+
+		if vfte.VideoFrameChunkSize != 0 && chunkSize != 0 && chunkSize != int64(vfte.VideoFrameChunkSize) {
+			rep.add(SeverityError, CheckChunkSize, vfte.VideoFrameChunkOffset, int64(vfte.VideoFrameChunkSize), chunkSize, "chunk size of video frame %d differs from VFTE entry", frame)
+		}
+	}
+
+	return chunkSizes
+}
+
+// validateVideoFrameChunk checks the MXJVVF64 chunk at the given offset, and returns its size.
+// If the chunk can't be read, 0 is returned.
+func (r *Reader) validateVideoFrameChunk(rep *Report, frame int, offset int64) int64 {
+	if _, err := r.accessor.Seek(offset, io.SeekStart); err != nil {
+		rep.add(SeverityError, CheckFrameChunk, offset, nil, nil, "failed to seek to chunk of video frame %d: %v", frame, err)
+		return 0
+	}
+	chunk, err := r.accessor.ReadChunk64()
+	if err != nil {
+		rep.add(SeverityError, CheckFrameChunk, offset, nil, nil, "failed to read chunk of video frame %d: %v", frame, err)
+		return 0
+	}
+	frameChunk, ok := chunk.(*mxriff64.Chunk64MXJVVF64)
+	if !ok {
+		rep.add(SeverityError, CheckFrameChunk, offset, frameChunk.Identifier(), chunk.Identifier(), "chunk of video frame %d is not a video frame chunk", frame)
+		return 0
+	}
+
+	// Check the SOI marker at the start, and the EOI marker at the end of the JPEG data.
+	var soi, eoi [2]byte
+	dataStart := offset + frameChunk.HeaderLength()
+	if frameChunk.Header.DataLength < 4 {
+		rep.add(SeverityError, CheckJPEGMarkers, offset, nil, frameChunk.Header.DataLength, "JPEG data of video frame %d is too short", frame)
+		return chunk.Length()
+	}
+	if _, err := r.accessor.Seek(dataStart, io.SeekStart); err != nil {
+		rep.add(SeverityError, CheckFrameChunk, offset, nil, nil, "failed to seek to JPEG data of video frame %d: %v", frame, err)
+		return chunk.Length()
+	}
+	if _, err := io.ReadFull(r.accessor, soi[:]); err != nil {
+		rep.add(SeverityError, CheckFrameChunk, offset, nil, nil, "failed to read JPEG data of video frame %d: %v", frame, err)
+		return chunk.Length()
+	}
+	if _, err := r.accessor.Seek(dataStart+frameChunk.Header.DataLength-2, io.SeekStart); err != nil {
+		rep.add(SeverityError, CheckFrameChunk, offset, nil, nil, "failed to seek to the end of the JPEG data of video frame %d: %v", frame, err)
+		return chunk.Length()
+	}
+	if _, err := io.ReadFull(r.accessor, eoi[:]); err != nil {
+		rep.add(SeverityError, CheckFrameChunk, offset, nil, nil, "failed to read JPEG data of video frame %d: %v", frame, err)
+		return chunk.Length()
+	}
+	if soi != [2]byte{0xFF, 0xD8} {
+		rep.add(SeverityError, CheckJPEGMarkers, offset, [2]byte{0xFF, 0xD8}, soi, "JPEG data of video frame %d doesn't start with a SOI marker", frame)
+	}
+	if eoi != [2]byte{0xFF, 0xD9} {
+		rep.add(SeverityWarning, CheckJPEGMarkers, offset, [2]byte{0xFF, 0xD9}, eoi, "JPEG data of video frame %d doesn't end with an EOI marker", frame)
+	}
+
+	return chunk.Length()
+}
+
+// validateAudioFrames checks all MXJVAF64 chunks referenced by the given AFTE entries.
+// It returns the actual chunk size for every entry, or 0 if the chunk couldn't be read.
+func (r *Reader) validateAudioFrames(rep *Report, aftes []mxriff64.Chunk32AFTEData) []int64 {
+	chunkSizes := make([]int64, len(aftes))
+
+	for frame, afte := range aftes {
+		offset := afte.AudioFrameChunkOffset
+		if afte.AudioFrameChunkSize == 0 {
+			rep.add(SeverityWarning, CheckZeroChunkSize, offset, nil, nil, "AFTE entry of audio frame %d has a chunk size of zero", frame)
+		}
+
+		if _, err := r.accessor.Seek(offset, io.SeekStart); err != nil {
+			rep.add(SeverityError, CheckFrameChunk, offset, nil, nil, "failed to seek to chunk of audio frame %d: %v", frame, err)
+			continue
+		}
+		chunk, err := r.accessor.ReadChunk64()
+		if err != nil {
+			rep.add(SeverityError, CheckFrameChunk, offset, nil, nil, "failed to read chunk of audio frame %d: %v", frame, err)
+			continue
+		}
+		frameChunk, ok := chunk.(*mxriff64.Chunk64MXJVAF64)
+		if !ok {
+			rep.add(SeverityError, CheckFrameChunk, offset, frameChunk.Identifier(), chunk.Identifier(), "chunk of audio frame %d is not an audio frame chunk", frame)
+			continue
+		}
+		chunkSizes[frame] = chunk.Length()
+
+		if afte.AudioFrameChunkSize != 0 && chunk.Length() != int64(afte.AudioFrameChunkSize) {
+			rep.add(SeverityError, CheckChunkSize, offset, int64(afte.AudioFrameChunkSize), chunk.Length(), "chunk size of audio frame %d differs from AFTE entry", frame)
+		}
+		if frameChunk.Data.StartSample != afte.StartSample {
+			rep.add(SeverityError, CheckAudioFrameData, offset, afte.StartSample, frameChunk.Data.StartSample, "start sample of audio frame %d differs from AFTE entry", frame)
+		}
+		if frameChunk.Data.Samples != afte.Samples {
+			rep.add(SeverityError, CheckAudioFrameData, offset, afte.Samples, frameChunk.Data.Samples, "number of samples of audio frame %d differs from AFTE entry", frame)
+		}
+		if want, got := int64(frameChunk.Data.Samples)*int64(r.Info.AudioBytesPerSample), frameChunk.Header.DataLength-16; want != got {
+			rep.add(SeverityError, CheckAudioFrameData, offset, want, got, "audio data size of audio frame %d doesn't match its number of samples", frame)
+		}
+	}
+
+	return chunkSizes
+}
+
+// validateMaxSizes compares the maximum chunk sizes in the header with the actual chunk sizes.
+func (r *Reader) validateMaxSizes(rep *Report, videoChunkSizes, audioChunkSizes []int64) {
+	var header *mxriff64.Chunk64MXJVHD64Data
+	var headerOffset int64
+	switch {
+	case r.chunkVideoHeader2 != nil:
+		header, headerOffset = &r.chunkVideoHeader2.Data.Chunk64MXJVHD64Data, r.chunkVideoHeader2.Offset()
+	case r.chunkVideoHeader != nil:
+		header, headerOffset = &r.chunkVideoHeader.Data, r.chunkVideoHeader.Offset()
+	default:
+		return
+	}
+
+	var maxVideoChunkSize, maxAudioChunkSize, maxReadSize int64
+	for frame, size := range videoChunkSizes {
+		maxVideoChunkSize = max(maxVideoChunkSize, size)
+		if frame < len(audioChunkSizes) {
+			size += audioChunkSizes[frame]
+		}
+		maxReadSize = max(maxReadSize, size)
+	}
+	for _, size := range audioChunkSizes {
+		maxAudioChunkSize = max(maxAudioChunkSize, size)
+	}
+	maxReadSize = min(maxReadSize, math.MaxUint32) // The header field is clamped to 32 bits, see Writer.
+
+	if int64(header.MaxJPEGSize) != maxVideoChunkSize {
+		rep.add(SeverityWarning, CheckMaxJPEGSize, headerOffset, maxVideoChunkSize, int64(header.MaxJPEGSize), "MaxJPEGSize differs from the size of the largest video frame chunk")
+	}
+	if int64(header.MaxReadSize) != maxReadSize {
+		rep.add(SeverityWarning, CheckMaxReadSize, headerOffset, maxReadSize, int64(header.MaxReadSize), "MaxReadSize differs from the largest video and audio frame chunk pair")
+	}
+	if r.chunkVideoHeader2 != nil && r.Info.HasAudio && int64(r.chunkVideoHeader2.Data.MaxAudioChunkSize) != maxAudioChunkSize {
+		rep.add(SeverityWarning, CheckMaxAudioChunkSize, headerOffset, maxAudioChunkSize, int64(r.chunkVideoHeader2.Data.MaxAudioChunkSize), "MaxAudioChunkSize differs from the size of the largest audio frame chunk")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestValidate(t *testing.T) {
+	filenames, err := filepath.Glob(filepath.Join("..", "example-files", "*.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to find files: %v.", err)
+	}
+
+	for _, filename := range filenames {
+		t.Run(filename, func(t *testing.T) {
+			f, err := os.Open(filename)
+			if err != nil {
+				t.Fatalf("Failed to open file: %v.", err)
+			}
+			defer f.Close()
+
+			report, err := mxv.Validate(f)
+			if err != nil {
+				t.Fatalf("Failed to validate file: %v.", err)
+			}
+			if len(report.Findings) != 0 {
+				t.Errorf("Unexpected findings:\n%s", report)
+			}
+		})
+	}
+}
+
+func TestValidateDamaged(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	mxvReader, err := mxv.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	firstVideoFrameOffset := int64(-1)
+	for _, vfte := range mxvReader.VideoFrames() {
+		firstVideoFrameOffset = vfte.VideoFrameChunkOffset
+		break
+	}
+	if firstVideoFrameOffset < 0 {
+		t.Fatalf("Couldn't find any video frame.")
+	}
+
+	// Change MaxJPEGSize in the MXJVH264 chunk, which is at offset 68 of the chunk data.
+	h264Offset := int64(bytes.Index(data, identifierMXJVH264[:]))
+	hd64Offset := int64(bytes.Index(data, identifierMXJVHD64[:]))
+	if h264Offset < 0 || hd64Offset < 0 {
+		t.Fatalf("Couldn't find video header chunks.")
+	}
+	maxJPEGSize := binary.LittleEndian.Uint32(data[h264Offset+16+68:])
+	binary.LittleEndian.PutUint32(data[h264Offset+16+68:], maxJPEGSize+1)
+
+	// Damage the SOI marker of the first video frame.
+	data[firstVideoFrameOffset+16] = 0
+
+	report, err := mxv.Validate(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to validate file: %v.", err)
+	}
+
+	want := []mxv.Finding{
+		{Severity: mxv.SeverityError, Check: mxv.CheckHeaderMismatch, Offset: hd64Offset, Expected: maxJPEGSize + 1, Actual: maxJPEGSize},
+		{Severity: mxv.SeverityError, Check: mxv.CheckJPEGMarkers, Offset: firstVideoFrameOffset, Expected: [2]byte{0xFF, 0xD8}, Actual: [2]byte{0x00, 0xD8}},
+		{Severity: mxv.SeverityWarning, Check: mxv.CheckMaxJPEGSize, Offset: h264Offset, Expected: int64(maxJPEGSize), Actual: int64(maxJPEGSize + 1)},
+	}
+	if !cmp.Equal(want, report.Findings, cmpopts.IgnoreFields(mxv.Finding{}, "Message")) {
+		t.Errorf("Unexpected findings:\n%s", cmp.Diff(want, report.Findings, cmpopts.IgnoreFields(mxv.Finding{}, "Message")))
+	}
+	if !report.HasErrors() {
+		t.Errorf("Report doesn't contain any errors.")
+	}
+
+	if _, err := mxv.NewReader(bytes.NewReader(data)); err == nil {
+		t.Errorf("Expected NewReader to fail because of contradicting video headers.")
+	}
+}
+
+// TestValidateMissingChunks checks that files, which NewReader refuses to open, still produce a report.
+func TestValidateMissingChunks(t *testing.T) {
+	original, err := os.ReadFile(filepath.Join("..", "example-files", "25p.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	tests := []struct {
+		name       string
+		identifier string // The identifier of the chunk that gets renamed, so that it is missing.
+		wantCheck  mxv.Check
+	}{
+		{"MXWFMT64", "MXWFMT64", mxv.CheckWaveFormat},
+		{"MXJVTL32", "MXJVTL32", mxv.CheckLookupList},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := bytes.Clone(original)
+			i := bytes.Index(data, []byte(tt.identifier))
+			if i < 0 {
+				t.Fatalf("Couldn't find %s chunk.", tt.identifier)
+			}
+			copy(data[i:], "XXXX")
+
+			// Without MXJVH264, the audio counts have to be reconstructed from the lookup list.
+			if i := bytes.Index(data, identifierMXJVH264[:]); i >= 0 {
+				copy(data[i:], "XXXX")
+			}
+
+			if _, err := mxv.NewReader(bytes.NewReader(data)); err == nil {
+				t.Errorf("Expected NewReader to fail.")
+			}
+
+			report, err := mxv.Validate(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Failed to validate file: %v.", err)
+			}
+			if !slices.ContainsFunc(report.Findings, func(f mxv.Finding) bool { return f.Check == tt.wantCheck && f.Severity == mxv.SeverityError }) {
+				t.Errorf("Report doesn't contain a %s error:\n%s", tt.wantCheck, report)
+			}
+		})
+	}
+}
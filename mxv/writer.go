@@ -0,0 +1,354 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+)
+
+// Writer creates MXV files from JPEG frames and raw audio data.
+//
+// The container is written in the same layout as files created by MAGIX software:
+// The video and audio headers, followed by the MXJVFL64 list with all frame chunks, the MXJVFT64 offset table and the MXJVTL32 lookup list.
+// The unknown MXJVCO64 and MXJVPD64 chunks are not written.
+//
+// If any method returns an error, the resulting file is most likely invalid.
+type Writer struct {
+	accessor *mxriff64.Accessor
+
+	chunkRoot      *mxriff64.Chunk64MXRIFF64
+	chunkFrameList *mxriff64.Chunk64MXLIST64 // Is nil once the writer is closed.
+
+	// Info contains the video and audio information the file is written with.
+	// The frame and sample counts are updated with every written frame.
+	Info Info
+
+	headerOffset     int64 // File offset of the MXJVH264 chunk.
+	frameTableOffset int64 // File offset of the MXJVFT64 chunk. Only known after the frame list is finished.
+
+	// List of written video frame chunk offsets.
+	videoFrameOffsets []mxriff64.Chunk32VFTEData
+
+	// List of written audio frame chunk offsets.
+	audioFrameOffsets []mxriff64.Chunk32AFTEData
+
+	maxVideoChunkSize uint32
+	maxAudioChunkSize uint64
+}
+
+// NewWriter creates a new MXV writer that writes into the given io.WriteSeeker.
+//
+// The following fields of info are used: ColorFormat, FrameWidth, FrameHeight, Framerate, AspectRatio, Interlaced and FieldOrder.
+// Unknown bits in Flags are written as they are.
+// If HasAudio is set, AudioFormat, AudioChannels, AudioSampleRate and AudioChannelBitDepth are used as well.
+// AudioByteRate and AudioBytesPerSample are derived from the other values if they are zero.
+// An AspectRatio of zero means square pixels.
+//
+// Close has to be called to finish the file.
+func NewWriter(ws io.WriteSeeker, info Info) (*Writer, error) {
+	if info.FrameWidth == 0 || info.FrameHeight == 0 {
+		return nil, fmt.Errorf("invalid frame size %dx%d", info.FrameWidth, info.FrameHeight)
+	}
+	if info.Framerate <= 0 {
+		return nil, fmt.Errorf("invalid framerate %v", info.Framerate)
+	}
+	if info.AspectRatio == 0 {
+		info.AspectRatio = float64(info.FrameWidth) / float64(info.FrameHeight)
+	}
+
+	if info.HasAudio {
+		if info.AudioChannels == 0 || info.AudioSampleRate == 0 || info.AudioChannelBitDepth == 0 {
+			return nil, fmt.Errorf("invalid audio format: %d channels, %d samples/s, %d bits", info.AudioChannels, info.AudioSampleRate, info.AudioChannelBitDepth)
+		}
+		if info.AudioBytesPerSample == 0 {
+			info.AudioBytesPerSample = uint16(uint32(info.AudioChannels) * ((info.AudioChannelBitDepth + 7) / 8))
+		}
+		if info.AudioByteRate == 0 {
+			info.AudioByteRate = info.AudioSampleRate * uint32(info.AudioBytesPerSample)
+		}
+	} else {
+		info.AudioFormat, info.AudioChannels, info.AudioSampleRate, info.AudioByteRate, info.AudioBytesPerSample, info.AudioChannelBitDepth = 0, 0, 0, 0, 0, 0
+	}
+
+	info.VideoFrames, info.AudioFrames, info.AudioSamples = 0, 0, 0
+
+	w := &Writer{
+		accessor: mxriff64.NewFromWriteSeeker(ws),
+		Info:     info,
+	}
+
+	w.chunkRoot = &mxriff64.Chunk64MXRIFF64{}
+	w.chunkRoot.Header.FormType = mxriff64.FormTypeMXJVID64
+	if err := w.chunkRoot.WriteChunk(w.accessor); err != nil {
+		return nil, fmt.Errorf("failed to write root chunk: %w", err)
+	}
+
+	// Write the headers with preliminary values, they will be updated by Close.
+	w.headerOffset = w.accessor.Pos
+	if err := w.writeHeaders(); err != nil {
+		return nil, err
+	}
+
+	if info.HasAudio {
+		chunkWaveFormat := &mxriff64.Chunk64MXWFMT64{
+			Data: mxriff64.Chunk64MXWFMT64Data{
+				AudioFormat:     info.AudioFormat,
+				Channels:        info.AudioChannels,
+				SampleRate:      info.AudioSampleRate,
+				ByteRate:        info.AudioByteRate,
+				BytesPerSample:  info.AudioBytesPerSample,
+				ChannelBitDepth: info.AudioChannelBitDepth,
+			},
+		}
+		if err := chunkWaveFormat.WriteChunk(w.accessor); err != nil {
+			return nil, fmt.Errorf("failed to write wave format chunk: %w", err)
+		}
+	}
+
+	w.chunkFrameList = &mxriff64.Chunk64MXLIST64{}
+	w.chunkFrameList.Header.ContentType = mxriff64.ContentTypeMXJVFL64
+	if err := w.chunkFrameList.WriteChunk(w.accessor); err != nil {
+		return nil, fmt.Errorf("failed to write frame list chunk: %w", err)
+	}
+
+	return w, nil
+}
+
+// writeHeaders writes the MXJVH264 and MXJVHD64 chunks at the current file offset.
+func (w *Writer) writeHeaders() error {
+	flags := w.Info.Flags.With(mxriff64.VideoFlagHasAudio, w.Info.HasAudio).WithFieldOrder(w.Info.Interlaced, w.Info.FieldOrder)
+
+	// The maximum size of any video and audio frame chunk pair.
+	// The sum may not fit into the 32-bit field, in which case it is clamped.
+	var maxReadSize uint64
+	for i, vfte := range w.videoFrameOffsets {
+		size := uint64(vfte.VideoFrameChunkSize)
+		if i < len(w.audioFrameOffsets) {
+			size += uint64(w.audioFrameOffsets[i].AudioFrameChunkSize)
+		}
+		maxReadSize = max(maxReadSize, size)
+	}
+
+	chunkVideoHeader := &mxriff64.Chunk64MXJVHD64{
+		Data: mxriff64.Chunk64MXJVHD64Data{
+			StructSize:       112, // The following unknown values are the same in all known MXV files.
+			Unknown1:         6,
+			FrameTableOffset: uint64(w.frameTableOffset),
+			VideoFrames:      w.Info.VideoFrames,
+			MaxReadSize:      uint32(min(maxReadSize, math.MaxUint32)),
+			Unknown2:         85,
+			Unknown3:         1,
+			Framerate:        w.Info.Framerate,
+			FrameWidth:       w.Info.FrameWidth,
+			FrameHeight:      w.Info.FrameHeight,
+			FrameWidth2:      w.Info.FrameWidth,
+			FrameHeight2:     w.Info.FrameHeight,
+			Flags:            flags,
+			MaxJPEGSize:      w.maxVideoChunkSize,
+		},
+	}
+
+	chunkVideoHeader2 := &mxriff64.Chunk64MXJVH264{
+		Data: mxriff64.Chunk64MXJVH264Data{
+			Chunk64MXJVHD64Data: chunkVideoHeader.Data,
+			AudioFrames:         w.Info.AudioFrames,
+			MaxAudioChunkSize:   w.maxAudioChunkSize,
+			AspectRatio:         w.Info.AspectRatio,
+			ColorFormat:         w.Info.ColorFormat,
+			AudioSamples:        w.Info.AudioSamples,
+		},
+	}
+
+	if err := chunkVideoHeader2.WriteChunk(w.accessor); err != nil {
+		return fmt.Errorf("failed to write video header chunk: %w", err)
+	}
+	if err := chunkVideoHeader.WriteChunk(w.accessor); err != nil {
+		return fmt.Errorf("failed to write video header chunk: %w", err)
+	}
+
+	return nil
+}
+
+// WriteVideoFrame adds a new video frame to the file.
+// The raw JPEG data is copied from the given io.Reader.
+//
+// Video and audio frames are stored in the order they are written.
+func (w *Writer) WriteVideoFrame(r io.Reader) error {
+	if w.chunkFrameList == nil {
+		return fmt.Errorf("the writer is already closed")
+	}
+
+	offset := w.accessor.Pos
+
+	chunk := &mxriff64.Chunk64MXJVVF64{}
+	if err := chunk.WriteChunk(w.accessor); err != nil {
+		return fmt.Errorf("failed to write video frame chunk: %w", err)
+	}
+	if _, err := io.Copy(w.accessor, r); err != nil {
+		return fmt.Errorf("failed to copy video frame data: %w", err)
+	}
+	if err := chunk.Close(); err != nil {
+		return fmt.Errorf("failed to finish video frame chunk: %w", err)
+	}
+
+	if chunk.Length() > int64(^uint32(0)) {
+		return fmt.Errorf("video frame chunk is too large. Got %d bytes, want at most %d bytes", chunk.Length(), ^uint32(0))
+	}
+	chunkSize := uint32(chunk.Length())
+
+	w.videoFrameOffsets = append(w.videoFrameOffsets, mxriff64.Chunk32VFTEData{VideoFrameChunkOffset: offset, VideoFrameChunkSize: chunkSize})
+	w.Info.VideoFrames++
+	w.maxVideoChunkSize = max(w.maxVideoChunkSize, chunkSize)
+
+	return nil
+}
+
+// RepeatVideoFrame adds a new video frame to the file that references the data of an already written video frame.
+// This doesn't store the frame data again.
+//
+// The range of valid frame numbers is [0...Info.VideoFrames-1].
+func (w *Writer) RepeatVideoFrame(frame int) error {
+	if w.chunkFrameList == nil {
+		return fmt.Errorf("the writer is already closed")
+	}
+
+	if frame < 0 || frame >= len(w.videoFrameOffsets) {
+		return fmt.Errorf("video frame %d is outside of the valid range from %d to %d", frame, 0, len(w.videoFrameOffsets)-1)
+	}
+
+	w.videoFrameOffsets = append(w.videoFrameOffsets, w.videoFrameOffsets[frame])
+	w.Info.VideoFrames++
+
+	return nil
+}
+
+// WriteAudioFrame adds a new audio frame with the given number of samples to the file.
+// The raw audio data is copied from the given io.Reader, its encoding has to match Info.AudioFormat.
+//
+// The frame starts right after the last sample of the previous audio frame.
+// The reader has to return exactly samples * Info.AudioBytesPerSample bytes.
+func (w *Writer) WriteAudioFrame(r io.Reader, samples uint32) error {
+	if w.chunkFrameList == nil {
+		return fmt.Errorf("the writer is already closed")
+	}
+
+	if !w.Info.HasAudio {
+		return fmt.Errorf("the file is written without audio")
+	}
+
+	offset := w.accessor.Pos
+	dataLength := int64(samples) * int64(w.Info.AudioBytesPerSample)
+
+	chunk := &mxriff64.Chunk64MXJVAF64{
+		Data: mxriff64.Chunk64MXJVAF64Data{
+			ChannelBitDepth: w.Info.AudioChannelBitDepth,
+			StartSample:     w.Info.AudioSamples,
+			Samples:         samples,
+		},
+	}
+	chunk.Header.DataLength = int64(binary.Size(chunk.Data)) + dataLength
+	if err := chunk.WriteChunk(w.accessor); err != nil {
+		return fmt.Errorf("failed to write audio frame chunk: %w", err)
+	}
+	if n, err := io.Copy(w.accessor, io.LimitReader(r, dataLength)); err != nil {
+		return fmt.Errorf("failed to copy audio frame data: %w", err)
+	} else if n != dataLength {
+		return fmt.Errorf("audio frame data is too short. Got %d bytes, want %d bytes", n, dataLength)
+	}
+	if err := chunk.Close(); err != nil {
+		return fmt.Errorf("failed to finish audio frame chunk: %w", err)
+	}
+
+	if chunk.Length() > int64(^uint32(0)) {
+		return fmt.Errorf("audio frame chunk is too large. Got %d bytes, want at most %d bytes", chunk.Length(), ^uint32(0))
+	}
+	chunkSize := uint32(chunk.Length())
+
+	w.audioFrameOffsets = append(w.audioFrameOffsets, mxriff64.Chunk32AFTEData{AudioFrameChunkOffset: offset, AudioFrameChunkSize: chunkSize, StartSample: w.Info.AudioSamples, Samples: samples})
+	w.Info.AudioFrames++
+	w.Info.AudioSamples += uint64(samples)
+	w.maxAudioChunkSize = max(w.maxAudioChunkSize, uint64(chunkSize))
+
+	return nil
+}
+
+// Close writes the frame tables and updates the headers.
+// This doesn't close the underlying io.WriteSeeker.
+func (w *Writer) Close() error {
+	if w.chunkFrameList == nil {
+		return fmt.Errorf("the writer is already closed")
+	}
+
+	if err := w.chunkFrameList.Close(); err != nil {
+		return fmt.Errorf("failed to finish frame list chunk: %w", err)
+	}
+	w.chunkFrameList = nil
+
+	// Write the frame table.
+	// It contains one more entry than there are video frames, which points to the end of the frame list.
+	w.frameTableOffset = w.accessor.Pos
+	chunkFrameTable := &mxriff64.Chunk64MXJVFT64{}
+	chunkFrameTable.Header.DataLength = int64(len(w.videoFrameOffsets)+1) * 8
+	if err := chunkFrameTable.WriteChunk(w.accessor); err != nil {
+		return fmt.Errorf("failed to write frame table chunk: %w", err)
+	}
+	for _, vfte := range w.videoFrameOffsets {
+		if err := binary.Write(w.accessor, binary.LittleEndian, vfte.VideoFrameChunkOffset); err != nil {
+			return fmt.Errorf("failed to write frame table entry: %w", err)
+		}
+	}
+	if err := binary.Write(w.accessor, binary.LittleEndian, w.frameTableOffset); err != nil {
+		return fmt.Errorf("failed to write frame table entry: %w", err)
+	}
+	if err := chunkFrameTable.Close(); err != nil {
+		return fmt.Errorf("failed to finish frame table chunk: %w", err)
+	}
+
+	// Write the lookup list.
+	chunkLookupList := &mxriff64.Chunk64MXLIST32{}
+	chunkLookupList.Header.ContentType = mxriff64.ContentTypeMXJVTL32
+	if err := chunkLookupList.WriteChunk(w.accessor); err != nil {
+		return fmt.Errorf("failed to write lookup list chunk: %w", err)
+	}
+	for _, vfte := range w.videoFrameOffsets {
+		chunk := &mxriff64.Chunk32VFTE{Data: vfte}
+		if err := chunk.WriteChunk(w.accessor); err != nil {
+			return fmt.Errorf("failed to write lookup list entry: %w", err)
+		}
+	}
+	for _, afte := range w.audioFrameOffsets {
+		chunk := &mxriff64.Chunk32AFTE{Data: afte}
+		if err := chunk.WriteChunk(w.accessor); err != nil {
+			return fmt.Errorf("failed to write lookup list entry: %w", err)
+		}
+	}
+	if err := chunkLookupList.Close(); err != nil {
+		return fmt.Errorf("failed to finish lookup list chunk: %w", err)
+	}
+
+	if err := w.chunkRoot.Close(); err != nil {
+		return fmt.Errorf("failed to finish root chunk: %w", err)
+	}
+
+	// Update the headers with the final values.
+	endOffset := w.accessor.Pos
+	if _, err := w.accessor.Seek(w.headerOffset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to the video headers: %w", err)
+	}
+	if err := w.writeHeaders(); err != nil {
+		return err
+	}
+	if _, err := w.accessor.Seek(endOffset, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek to the end of the file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package mxv_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestWriterRemux reads an example file and writes all its frames into a new MXV file.
+// The result has to contain the same information and frame data.
+func TestWriterRemux(t *testing.T) {
+	src, err := os.Open(filepath.Join("..", "example-files", "29.97i.mxv"))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	defer src.Close()
+
+	srcReader, err := mxv.NewReader(src)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+	if err := srcReader.PrepareLookupTable(); err != nil {
+		t.Fatalf("Failed to prepare lookup table: %v.", err)
+	}
+
+	dstFilename := filepath.Join(t.TempDir(), "remuxed.mxv")
+	dst, err := os.Create(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer dst.Close()
+
+	mxvWriter, err := mxv.NewWriter(dst, srcReader.Info)
+	if err != nil {
+		t.Fatalf("Failed to create MXV writer: %v.", err)
+	}
+
+	// Interleave video and audio frames, and deduplicate video frames.
+	writtenFrames := map[int64]int{}
+	for frame, vfte := range srcReader.VideoFrames() {
+		if writtenFrame, ok := writtenFrames[vfte.VideoFrameChunkOffset]; ok {
+			if err := mxvWriter.RepeatVideoFrame(writtenFrame); err != nil {
+				t.Fatalf("Failed to repeat video frame: %v.", err)
+			}
+		} else {
+			r, err := srcReader.VideoFrameData(frame)
+			if err != nil {
+				t.Fatalf("Failed to get video data stream: %v.", err)
+			}
+			if err := mxvWriter.WriteVideoFrame(r); err != nil {
+				t.Fatalf("Failed to write video frame: %v.", err)
+			}
+			writtenFrames[vfte.VideoFrameChunkOffset] = frame
+		}
+
+		if uint64(frame) < srcReader.Info.AudioFrames {
+			r, _, samples, err := srcReader.AudioFrameData(frame)
+			if err != nil {
+				t.Fatalf("Failed to get audio data stream: %v.", err)
+			}
+			if err := mxvWriter.WriteAudioFrame(r, samples); err != nil {
+				t.Fatalf("Failed to write audio frame: %v.", err)
+			}
+		}
+	}
+
+	if err := mxvWriter.Close(); err != nil {
+		t.Fatalf("Failed to close MXV writer: %v.", err)
+	}
+
+	if _, err := dst.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v.", err)
+	}
+	dstReader, err := mxv.NewReader(dst)
+	if err != nil {
+		t.Fatalf("Failed to read written MXV file: %v.", err)
+	}
+	if err := dstReader.PrepareLookupTable(); err != nil {
+		t.Fatalf("Failed to prepare lookup table of written MXV file: %v.", err)
+	}
+
+	if !cmp.Equal(srcReader.Info, dstReader.Info) {
+		t.Errorf("Written video info differs from source:\n%s", cmp.Diff(srcReader.Info, dstReader.Info))
+	}
+
+	readAll := func(r io.Reader, err error) []byte {
+		if err != nil {
+			t.Fatalf("Failed to get data stream: %v.", err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read data stream: %v.", err)
+		}
+		return data
+	}
+
+	for frame := range srcReader.VideoFrames() {
+		want := readAll(srcReader.VideoFrameData(frame))
+		got := readAll(dstReader.VideoFrameData(frame))
+		if !bytes.Equal(got, want) {
+			t.Errorf("Video frame %d differs from source. Got %d bytes, want %d bytes.", frame, len(got), len(want))
+		}
+	}
+
+	for frame := range srcReader.AudioFrames() {
+		r, _, _, err := srcReader.AudioFrameData(frame)
+		want := readAll(r, err)
+		r, _, _, err = dstReader.AudioFrameData(frame)
+		got := readAll(r, err)
+		if !bytes.Equal(got, want) {
+			t.Errorf("Audio frame %d differs from source. Got %d bytes, want %d bytes.", frame, len(got), len(want))
+		}
+	}
+}
+
+// TestWriterSynthetic writes a small synthetic MXV file with repeated video frames.
+func TestWriterSynthetic(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "synthetic.mxv")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	info := mxv.Info{
+		ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 16, FrameHeight: 8, Framerate: 25, Flags: 1 << 20, Interlaced: true, FieldOrder: mxriff64.FieldOrderBottomFirst,
+		HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioChannelBitDepth: 16,
+	}
+
+	mxvWriter, err := mxv.NewWriter(f, info)
+	if err != nil {
+		t.Fatalf("Failed to create MXV writer: %v.", err)
+	}
+
+	frames := [][]byte{{0xFF, 0xD8, 1, 0xFF, 0xD9}, {0xFF, 0xD8, 2, 2, 0xFF, 0xD9}}
+	for _, frame := range frames {
+		if err := mxvWriter.WriteVideoFrame(bytes.NewReader(frame)); err != nil {
+			t.Fatalf("Failed to write video frame: %v.", err)
+		}
+	}
+	if err := mxvWriter.RepeatVideoFrame(0); err != nil {
+		t.Fatalf("Failed to repeat video frame: %v.", err)
+	}
+	for range 3 {
+		if err := mxvWriter.WriteAudioFrame(bytes.NewReader(make([]byte, 1920*4)), 1920); err != nil {
+			t.Fatalf("Failed to write audio frame: %v.", err)
+		}
+	}
+	if err := mxvWriter.Close(); err != nil {
+		t.Fatalf("Failed to close MXV writer: %v.", err)
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Failed to seek: %v.", err)
+	}
+	mxvReader, err := mxv.NewReader(f)
+	if err != nil {
+		t.Fatalf("Failed to read written MXV file: %v.", err)
+	}
+	if err := mxvReader.PrepareLookupTable(); err != nil {
+		t.Fatalf("Failed to prepare lookup table of written MXV file: %v.", err)
+	}
+
+	wantInfo := mxv.Info{
+		VideoHeader: identifierMXJVH264,
+		ColorFormat: mxriff64.ColorFormatYV12, FrameWidth: 16, FrameHeight: 8, Framerate: 25, FramerateNum: 25, FramerateDen: 1, VideoFrames: 3, AspectRatio: 2,
+		Flags: 1<<20 | mxriff64.VideoFlagHasAudio | mxriff64.VideoFlagInterlaced | mxriff64.VideoFlagBottomFieldFirst, Interlaced: true, FieldOrder: mxriff64.FieldOrderBottomFirst,
+		HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 2, AudioSampleRate: 48000, AudioByteRate: 192000,
+		AudioBytesPerSample: 4, AudioChannelBitDepth: 16, AudioFrames: 3, AudioSamples: 5760,
+	}
+	if !cmp.Equal(wantInfo, mxvReader.Info) {
+		t.Errorf("Parsed video info differs from expected result:\n%s", cmp.Diff(wantInfo, mxvReader.Info))
+	}
+
+	for frame, want := range append(frames, frames[0]) {
+		r, err := mxvReader.VideoFrameData(frame)
+		if err != nil {
+			t.Fatalf("Failed to get video data stream: %v.", err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read video data stream: %v.", err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Video frame %d differs. Got %v, want %v.", frame, got, want)
+		}
+	}
+}
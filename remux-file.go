@@ -0,0 +1,85 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Dadido3/mxv-demuxer/avi"
+	"github.com/Dadido3/mxv-demuxer/mkv"
+	"github.com/Dadido3/mxv-demuxer/mov"
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// remuxOptions contains the settings for remuxing into another container.
+type remuxOptions struct {
+	fieldOrder string // Either "auto", "progressive", "top-first" or "bottom-first".
+}
+
+// remuxFile will remux the given file into a new container of the given format.
+// The result is written next to the source file, with the file extension replaced.
+func remuxFile(filename, format string, readerOptions mxv.ReaderOptions, options remuxOptions) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	mxvReader, err := mxv.NewReaderWithOptions(file, readerOptions)
+	if err != nil {
+		return fmt.Errorf("failed to read MXV file: %w", err)
+	}
+
+	// The interlacing can't be detected reliably, so it can be overridden.
+	switch options.fieldOrder {
+	case "progressive":
+		mxvReader.Info.Interlaced = false
+	case "top-first":
+		mxvReader.Info.Interlaced, mxvReader.Info.FieldOrder = true, mxriff64.FieldOrderTopFirst
+	case "bottom-first":
+		mxvReader.Info.Interlaced, mxvReader.Info.FieldOrder = true, mxriff64.FieldOrderBottomFirst
+	}
+
+	log.Printf("MXV info: %+v.", mxvReader.Info)
+	logFrameTableDisagreements(mxvReader)
+
+	outputFilename := strings.TrimSuffix(filename, filepath.Ext(filename)) + "." + format
+	output, err := os.Create(outputFilename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer output.Close()
+
+	log.Printf("Writing %q.", outputFilename)
+
+	switch format {
+	case "avi":
+		err = avi.Remux(output, mxvReader)
+	case "mkv":
+		err = mkv.Remux(output, mxvReader)
+	case "mov":
+		err = mov.Remux(output, mxvReader)
+	default:
+		err = fmt.Errorf("unsupported output format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remux into %q: %w", outputFilename, err)
+	}
+
+	if err := output.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+
+	logReaderWarnings(mxvReader)
+	log.Printf("Completely remuxed %q.", filename)
+
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// validateFile will check the given file for problems, and log every finding.
+func validateFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	report, err := mxv.Validate(file)
+	if err != nil {
+		return fmt.Errorf("failed to validate MXV file: %w", err)
+	}
+
+	for _, finding := range report.Findings {
+		log.Printf("%v.", finding)
+	}
+	log.Printf("Found %d problems in %q.", len(report.Findings), filename)
+
+	return nil
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package wav
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// Remux writes all audio frames of the given MXV reader as WAV file into ws.
+//
+// The audio data is copied as is, there is no transcoding.
+// Gaps between audio frames are filled with silence, see mxv.AudioStream.
+// Only a single audio frame is held in memory at a time, so there is no limit on the length of the audio.
+func Remux(ws io.WriteSeeker, r *mxv.Reader) error {
+	if err := r.PrepareLookupTable(); err != nil {
+		return fmt.Errorf("failed to prepare lookup table: %w", err)
+	}
+
+	w, err := NewWriter(ws, r.Info)
+	if err != nil {
+		return fmt.Errorf("failed to create WAV writer: %w", err)
+	}
+
+	stream, err := r.AudioStream()
+	if err != nil {
+		return fmt.Errorf("failed to get audio stream: %w", err)
+	}
+
+	for written := uint64(0); written < stream.Samples(); {
+		samples := uint32(min(stream.Samples()-written, math.MaxUint32))
+		if err := w.WriteAudioFrame(stream, samples); err != nil {
+			return fmt.Errorf("failed to write audio at sample %d: %w", written, err)
+		}
+		written += uint64(samples)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to finish WAV file: %w", err)
+	}
+
+	return nil
+}
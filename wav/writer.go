@@ -0,0 +1,210 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+// Package wav implements a streaming WAV encoder that writes the PCM audio of MXV files.
+//
+// The audio data is written directly to the output, and the sizes in the headers are updated by Close.
+// If the file exceeds the 4 GiB limit of RIFF, it is written as RF64 file instead.
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/Dadido3/mxv-demuxer/internal/waveformat"
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// The maximum size of a RIFF chunk that can be stored in its 32-bit size field.
+// Larger files are written as RF64.
+var maxRIFFSize int64 = math.MaxUint32
+
+// The ds64 chunk data of RF64 files, which contains the 64-bit sizes.
+type dataSize64 struct {
+	RIFFSize    uint64
+	DataSize    uint64
+	SampleCount uint64
+	TableLength uint32 // Number of additional chunk sizes. Always 0.
+}
+
+// Writer creates WAV files with uncompressed audio.
+//
+// If any method returns an error, the resulting file is most likely invalid.
+type Writer struct {
+	ws  io.WriteSeeker
+	pos int64 // Current file offset.
+
+	info mxv.Info
+
+	ds64Offset int64 // File offset of the JUNK chunk that is replaced by a ds64 chunk for RF64 files.
+	dataOffset int64 // File offset of the data chunk.
+
+	closed bool
+}
+
+// NewWriter creates a new WAV writer that writes into the given io.WriteSeeker.
+//
+// The audio format is taken from the given MXV info.
+// Close has to be called to finish the file.
+func NewWriter(ws io.WriteSeeker, info mxv.Info) (*Writer, error) {
+	if !info.HasAudio {
+		return nil, fmt.Errorf("the MXV file doesn't contain any audio")
+	}
+	if info.AudioBytesPerSample == 0 || info.AudioSampleRate == 0 || info.AudioChannels == 0 {
+		return nil, fmt.Errorf("invalid audio format: %d bytes per sample, %d samples/s, %d channels", info.AudioBytesPerSample, info.AudioSampleRate, info.AudioChannels)
+	}
+	switch info.AudioFormat {
+	case mxriff64.AudioFormatPCM, mxriff64.AudioFormatIEEEFloat:
+	default:
+		return nil, fmt.Errorf("unsupported audio format %v", info.AudioFormat)
+	}
+
+	w := &Writer{
+		ws:   ws,
+		info: info,
+	}
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, [4]byte{'R', 'I', 'F', 'F'}) // Writing into a bytes.Buffer never fails for fixed size data.
+	binary.Write(&buf, binary.LittleEndian, uint32(0))                   // Updated by Close.
+	binary.Write(&buf, binary.LittleEndian, [4]byte{'W', 'A', 'V', 'E'})
+
+	// Reserve space for the ds64 chunk, in case the file has to be written as RF64.
+	w.ds64Offset = int64(buf.Len())
+	binary.Write(&buf, binary.LittleEndian, [4]byte{'J', 'U', 'N', 'K'})
+	binary.Write(&buf, binary.LittleEndian, uint32(binary.Size(dataSize64{})))
+	binary.Write(&buf, binary.LittleEndian, dataSize64{})
+
+	format, extensible := waveformat.New(info)
+	binary.Write(&buf, binary.LittleEndian, [4]byte{'f', 'm', 't', ' '})
+	switch {
+	case extensible != nil:
+		binary.Write(&buf, binary.LittleEndian, uint32(binary.Size(format)+binary.Size(extensible)))
+		binary.Write(&buf, binary.LittleEndian, format)
+		binary.Write(&buf, binary.LittleEndian, extensible)
+	case info.AudioFormat == mxriff64.AudioFormatPCM:
+		binary.Write(&buf, binary.LittleEndian, uint32(binary.Size(format)))
+		binary.Write(&buf, binary.LittleEndian, format)
+	default:
+		// Any format other than PCM has the cbSize field, even if there is no extra information.
+		binary.Write(&buf, binary.LittleEndian, uint32(binary.Size(format)+2))
+		binary.Write(&buf, binary.LittleEndian, format)
+		binary.Write(&buf, binary.LittleEndian, uint16(0))
+	}
+
+	w.dataOffset = int64(buf.Len())
+	binary.Write(&buf, binary.LittleEndian, [4]byte{'d', 'a', 't', 'a'})
+	binary.Write(&buf, binary.LittleEndian, uint32(0)) // Updated by Close.
+
+	if err := w.write(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to write headers: %w", err)
+	}
+
+	return w, nil
+}
+
+// write writes the given data at the current file offset.
+func (w *Writer) write(data []byte) error {
+	n, err := w.ws.Write(data)
+	w.pos += int64(n)
+	return err
+}
+
+// writeAt writes the given data at the given file offset, and restores the current file offset afterwards.
+func (w *Writer) writeAt(offset int64, data []byte) error {
+	if wa, ok := w.ws.(io.WriterAt); ok {
+		_, err := wa.WriteAt(data, offset)
+		return err
+	}
+
+	if _, err := w.ws.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := w.ws.Write(data); err != nil {
+		return err
+	}
+	if _, err := w.ws.Seek(w.pos, io.SeekStart); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WriteAudioFrame adds the given number of audio samples to the file.
+// The raw audio data is copied from the given io.Reader, its encoding has to match the audio format of the MXV info.
+func (w *Writer) WriteAudioFrame(r io.Reader, samples uint32) error {
+	if w.closed {
+		return fmt.Errorf("the writer is already closed")
+	}
+
+	size := int64(samples) * int64(w.info.AudioBytesPerSample)
+	n, err := io.Copy(w.ws, io.LimitReader(r, size))
+	w.pos += n
+	if err != nil {
+		return fmt.Errorf("failed to copy audio data: %w", err)
+	}
+	if n != size {
+		return fmt.Errorf("audio frame contains only %d of %d bytes", n, size)
+	}
+
+	return nil
+}
+
+// Close updates the sizes in the headers.
+// If the file is too large for RIFF, it is turned into a RF64 file.
+// This doesn't close the underlying io.WriteSeeker.
+func (w *Writer) Close() error {
+	if w.closed {
+		return fmt.Errorf("the writer is already closed")
+	}
+	w.closed = true
+
+	dataSize := w.pos - w.dataOffset - 8
+
+	// Chunks have to be aligned to 2 bytes.
+	if dataSize%2 != 0 {
+		if err := w.write([]byte{0}); err != nil {
+			return fmt.Errorf("failed to write padding byte: %w", err)
+		}
+	}
+
+	riffSize := w.pos - 8
+
+	if riffSize <= maxRIFFSize {
+		if err := w.writeAt(4, binary.LittleEndian.AppendUint32(nil, uint32(riffSize))); err != nil {
+			return fmt.Errorf("failed to update RIFF size: %w", err)
+		}
+		if err := w.writeAt(w.dataOffset+4, binary.LittleEndian.AppendUint32(nil, uint32(dataSize))); err != nil {
+			return fmt.Errorf("failed to update data size: %w", err)
+		}
+		return nil
+	}
+
+	// The sizes don't fit into 32 bits, turn the file into RF64.
+	// The 32-bit size fields are set to -1, and the real sizes are stored in the ds64 chunk that replaces the JUNK chunk.
+	if err := w.writeAt(0, []byte{'R', 'F', '6', '4', 0xFF, 0xFF, 0xFF, 0xFF}); err != nil {
+		return fmt.Errorf("failed to update RF64 header: %w", err)
+	}
+	var ds64 bytes.Buffer
+	binary.Write(&ds64, binary.LittleEndian, [4]byte{'d', 's', '6', '4'})
+	binary.Write(&ds64, binary.LittleEndian, uint32(binary.Size(dataSize64{})))
+	binary.Write(&ds64, binary.LittleEndian, dataSize64{
+		RIFFSize:    uint64(riffSize),
+		DataSize:    uint64(dataSize),
+		SampleCount: uint64(dataSize) / uint64(w.info.AudioBytesPerSample),
+	})
+	if err := w.writeAt(w.ds64Offset, ds64.Bytes()); err != nil {
+		return fmt.Errorf("failed to write ds64 chunk: %w", err)
+	}
+	if err := w.writeAt(w.dataOffset+4, []byte{0xFF, 0xFF, 0xFF, 0xFF}); err != nil {
+		return fmt.Errorf("failed to update data size: %w", err)
+	}
+
+	return nil
+}
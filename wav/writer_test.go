@@ -0,0 +1,216 @@
+// Copyright (c) 2025 David Vogel
+//
+// This software is released under the MIT License.
+// https://opensource.org/licenses/MIT
+
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Dadido3/mxv-demuxer/internal/waveformat"
+	"github.com/Dadido3/mxv-demuxer/mxriff64"
+	"github.com/Dadido3/mxv-demuxer/mxv"
+)
+
+// waveChunk is a parsed sub-chunk of a WAV file.
+type waveChunk struct {
+	id   string
+	size uint32 // Size as stored in the chunk header.
+	data []byte
+}
+
+// parseWAVE parses the header and all sub-chunks of the given RIFF or RF64 file.
+// The sizes of RF64 files are taken from their ds64 chunk.
+func parseWAVE(t *testing.T, data []byte) (id string, riffSize uint64, chunks map[string]waveChunk) {
+	if len(data) < 12 || string(data[8:12]) != "WAVE" {
+		t.Fatalf("Missing WAVE header.")
+	}
+	id, riffSize = string(data[0:4]), uint64(binary.LittleEndian.Uint32(data[4:8]))
+
+	var ds64 dataSize64
+	chunks = map[string]waveChunk{}
+	for offset := uint64(12); offset < uint64(len(data)); {
+		if uint64(len(data)) < offset+8 {
+			t.Fatalf("Truncated chunk header at offset %d.", offset)
+		}
+		c := waveChunk{id: string(data[offset : offset+4]), size: binary.LittleEndian.Uint32(data[offset+4:])}
+		size := uint64(c.size)
+		if id == "RF64" && c.id == "data" && size == 0xFFFFFFFF {
+			size = ds64.DataSize
+		}
+		if uint64(len(data)) < offset+8+size {
+			t.Fatalf("Chunk %q at offset %d goes beyond the end of the file.", c.id, offset)
+		}
+		c.data = data[offset+8 : offset+8+size]
+		if c.id == "ds64" {
+			binary.Read(bytes.NewReader(c.data), binary.LittleEndian, &ds64)
+		}
+		chunks[c.id] = c
+
+		offset += 8 + size + size%2
+	}
+
+	if id == "RF64" {
+		riffSize = ds64.RIFFSize
+	}
+
+	return id, riffSize, chunks
+}
+
+// remuxFile remuxes the audio of the given example file into a WAV file, and returns the reader and the written file.
+func remuxFile(t *testing.T, name string) (*mxv.Reader, []byte) {
+	src, err := os.Open(filepath.Join("..", "example-files", name))
+	if err != nil {
+		t.Fatalf("Failed to open file: %v.", err)
+	}
+	t.Cleanup(func() { src.Close() })
+
+	mxvReader, err := mxv.NewReader(src)
+	if err != nil {
+		t.Fatalf("Failed to read MXV file: %v.", err)
+	}
+
+	dstFilename := filepath.Join(t.TempDir(), "audio.wav")
+	dst, err := os.Create(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer dst.Close()
+
+	if err := Remux(dst, mxvReader); err != nil {
+		t.Fatalf("Failed to remux: %v.", err)
+	}
+
+	data, err := os.ReadFile(dstFilename)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+
+	return mxvReader, data
+}
+
+// audioData returns the concatenated audio data of all frames.
+func audioData(t *testing.T, mxvReader *mxv.Reader) []byte {
+	var result []byte
+	for frame := range mxvReader.AudioFrames() {
+		r, _, _, err := mxvReader.AudioFrameData(frame)
+		if err != nil {
+			t.Fatalf("Failed to get audio data stream: %v.", err)
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("Failed to read audio data stream: %v.", err)
+		}
+		result = append(result, data...)
+	}
+	return result
+}
+
+func TestRemux(t *testing.T) {
+	mxvReader, data := remuxFile(t, "25p.mxv")
+	info := mxvReader.Info
+
+	id, riffSize, chunks := parseWAVE(t, data)
+	if id != "RIFF" {
+		t.Errorf("Unexpected file type %q, want %q.", id, "RIFF")
+	}
+	if got, want := riffSize, uint64(len(data)-8); got != want {
+		t.Errorf("Unexpected RIFF size. Got %d, want %d.", got, want)
+	}
+
+	var format waveformat.WaveFormat
+	binary.Read(bytes.NewReader(chunks["fmt "].data), binary.LittleEndian, &format)
+	want := waveformat.WaveFormat{
+		FormatTag:      uint16(info.AudioFormat),
+		Channels:       info.AudioChannels,
+		SamplesPerSec:  info.AudioSampleRate,
+		AvgBytesPerSec: info.AudioByteRate,
+		BlockAlign:     info.AudioBytesPerSample,
+		BitsPerSample:  uint16(info.AudioChannelBitDepth),
+	}
+	if format != want {
+		t.Errorf("Unexpected format. Got %+v, want %+v.", format, want)
+	}
+
+	if !bytes.Equal(chunks["data"].data, audioData(t, mxvReader)) {
+		t.Errorf("Audio data differs from source.")
+	}
+	if got, want := uint64(len(chunks["data"].data)), info.AudioSamples*uint64(info.AudioBytesPerSample); got != want {
+		t.Errorf("Unexpected audio data length. Got %d bytes, want %d bytes.", got, want)
+	}
+}
+
+func TestRemuxRF64(t *testing.T) {
+	// Use a small limit to test RF64 without writing 4 GiB.
+	defer func(size int64) { maxRIFFSize = size }(maxRIFFSize)
+	maxRIFFSize = 1 << 10
+
+	mxvReader, data := remuxFile(t, "25p.mxv")
+
+	id, riffSize, chunks := parseWAVE(t, data)
+	if id != "RF64" {
+		t.Errorf("Unexpected file type %q, want %q.", id, "RF64")
+	}
+	if got := binary.LittleEndian.Uint32(data[4:8]); got != 0xFFFFFFFF {
+		t.Errorf("Unexpected 32-bit RIFF size %#x, want %#x.", got, 0xFFFFFFFF)
+	}
+	if got, want := riffSize, uint64(len(data)-8); got != want {
+		t.Errorf("Unexpected RIFF size. Got %d, want %d.", got, want)
+	}
+	if _, ok := chunks["JUNK"]; ok {
+		t.Errorf("The JUNK chunk wasn't replaced by the ds64 chunk.")
+	}
+
+	var ds64 dataSize64
+	binary.Read(bytes.NewReader(chunks["ds64"].data), binary.LittleEndian, &ds64)
+	if got, want := ds64.SampleCount, mxvReader.Info.AudioSamples; got != want {
+		t.Errorf("Unexpected sample count. Got %d, want %d.", got, want)
+	}
+	if got := chunks["data"].size; got != 0xFFFFFFFF {
+		t.Errorf("Unexpected 32-bit data size %#x, want %#x.", got, 0xFFFFFFFF)
+	}
+	if !bytes.Equal(chunks["data"].data, audioData(t, mxvReader)) {
+		t.Errorf("Audio data differs from source.")
+	}
+}
+
+// TestNewWriterExtensible checks that multichannel and high bit depth PCM is written as WAVE_FORMAT_EXTENSIBLE.
+func TestNewWriterExtensible(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "audio.wav"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v.", err)
+	}
+	defer f.Close()
+
+	w, err := NewWriter(f, mxv.Info{HasAudio: true, AudioFormat: mxriff64.AudioFormatPCM, AudioChannels: 6, AudioSampleRate: 48000, AudioBytesPerSample: 18, AudioChannelBitDepth: 24})
+	if err != nil {
+		t.Fatalf("Failed to create WAV writer: %v.", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close WAV writer: %v.", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("Failed to read file: %v.", err)
+	}
+	_, _, chunks := parseWAVE(t, data)
+
+	var format struct {
+		waveformat.WaveFormat
+		waveformat.Extensible
+	}
+	if got, want := len(chunks["fmt "].data), binary.Size(format); got != want {
+		t.Fatalf("Unexpected fmt chunk size. Got %d, want %d.", got, want)
+	}
+	binary.Read(bytes.NewReader(chunks["fmt "].data), binary.LittleEndian, &format)
+	if format.FormatTag != waveformat.FormatTagExtensible || format.BitsPerSample != 24 || format.ValidBitsPerSample != 24 || format.ChannelMask != 0x3F || format.SubFormat[0] != 1 {
+		t.Errorf("Unexpected format %+v.", format)
+	}
+}